@@ -1,62 +1,323 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/databases/mariadb"
+	"github.com/awade12/go-db/src/databases/mysql"
 	"github.com/awade12/go-db/src/databases/postgres"
 	"github.com/awade12/go-db/src/flags"
+	"github.com/awade12/go-db/src/stack"
 	"github.com/awade12/go-db/src/system"
 	"github.com/awade12/go-db/src/utils"
+	"github.com/awade12/go-db/src/version"
 )
 
 func printUsage() {
-	fmt.Println("Usage: go-db <command> <database-type> [options]")
+	fmt.Println(version.Get())
+	fmt.Println("\nUsage: go-db <command> <database-type> [options]")
 	fmt.Println("\nCommands:")
 	fmt.Println("  create         Create a new database (requires name)")
 	fmt.Println("  create-custom  Create a new database with custom configuration")
 	fmt.Println("  start          Start a stopped database")
 	fmt.Println("  stop           Stop a running database")
 	fmt.Println("  remove         Remove a database container")
-	fmt.Println("  list           List all database containers")
+	fmt.Println("  list           List all database containers created by go-db (list <type> for one type)")
+	fmt.Println("  prune          List (or with --cleanup-volumes remove) dangling volumes created by go-db")
+	fmt.Println("  logs           Print the logs of a database container")
+	fmt.Println("  connect        Open an interactive psql session in a database container")
+	fmt.Println("  exec           Run a command inside a database container")
 	fmt.Println("  show           Show connection details for a database container")
+	fmt.Println("  inspect        Print a database container's metadata as JSON")
+	fmt.Println("  backup         Dump a database to a local file")
+	fmt.Println("  restore        Load a dump file into a database")
+	fmt.Println("  apply          Create every database of a stack file (YAML, TOML or JSON) in dependency order")
+	fmt.Println("  validate       Check a stack file or create-custom config file without creating anything")
+	fmt.Println("  down           Remove the databases a stack apply created (alias: destroy)")
+	fmt.Println("  health         Check that a database is up and accepting connections (exits non-zero if not)")
+	fmt.Println("  clone          Copy a database into a new container through pg_dump (source must be running)")
+	fmt.Println("  rename         Rename a database container (the database inside keeps its name)")
+	fmt.Println("  autostop       Stop PostgreSQL containers that had no connections for --idle (runs until Ctrl-C)")
+	fmt.Println("  upgrade        Move a container to another version (--version), keeping the old one as <name>.bak (alias: update)")
+	fmt.Println("  rollback       Undo an upgrade by bringing back the <name>.bak container")
+	fmt.Println("  maintenance    Run VACUUM, ANALYZE or REINDEX against a database")
+	fmt.Println("  bench          Run a pgbench performance smoke test")
+	fmt.Println("  tables         List the tables of a database with their sizes")
+	fmt.Println("  databases      List the databases of a container")
+	fmt.Println("  users          List the roles of a container")
+	fmt.Println("  metrics        Print database statistics in the Prometheus text format")
+	fmt.Println("  versions       List the versions available for a database type")
+	fmt.Println("  version        Print the go-db version, commit and build date (--json for JSON; alias: --version)")
 	fmt.Println("  install-docker Install Docker on the current system")
+	fmt.Println("\nAliases:")
+	fmt.Println("  rm → remove, ls / ps → list, new → create, log → logs, psql → connect")
 	fmt.Println("\nDatabase Types:")
 	fmt.Println("  postgres       PostgreSQL database")
+	fmt.Println("  mysql          MySQL database (create, create-custom, start, stop, remove, list mysql, show)")
+	fmt.Println("  mariadb        MariaDB database (create, create-custom, start, stop, remove, list mariadb, show)")
 	fmt.Println("\nCustom Mode Options (for create-custom):")
 	fmt.Println("  --name         Container and database name (required)")
 	fmt.Println("  --version      PostgreSQL version (default: 15)")
 	fmt.Println("  --port         Port to expose (default: 5432)")
-	fmt.Println("  --password     Database password")
+	fmt.Println("  --password     Database password (default: generated)")
+	fmt.Println("  --password-file Read the password from a file; precedence: --password-file, $GODB_PASSWORD, --password, generated")
 	fmt.Println("  --user         Database user")
 	fmt.Println("  --db           Database name")
-	fmt.Println("  --volume       Data volume path for persistence")
+	fmt.Println("  --volume       Data volume for persistence: a host path (bind mount) or a docker volume name")
+	fmt.Println("  --volume-driver Driver to create a new named --volume with (e.g. an NFS plugin)")
+	fmt.Println("  --require-encrypted-volume Refuse to create unless the volume's storage is LUKS/dm-crypt encrypted (checked with lsblk/cryptsetup on Linux)")
 	fmt.Println("  --memory       Memory limit (e.g., '1g')")
+	fmt.Println("  --memory-swap  Memory plus swap limit (e.g., '2g', -1 for unlimited swap)")
+	fmt.Println("  --shm-size     Size of /dev/shm (default: 256m)")
 	fmt.Println("  --cpu          CPU limit (e.g., '0.5')")
-	fmt.Println("  --timezone     Container timezone (default: UTC)")
+	fmt.Println("  --mem-auto     Memory limit from the host's RAM (--mem-fraction, default 0.25); shared_buffers gets a quarter of it")
+	fmt.Println("  --restart      Docker restart policy: no (default), on-failure, always or unless-stopped")
+	fmt.Println("  --stop-signal  Signal docker stop sends: SIGTERM (smart), SIGINT (fast) or SIGQUIT (immediate shutdown)")
+	fmt.Println("  --timezone     Container timezone, a tz database name such as Europe/Berlin or UTC[+-]hh[:mm] (default: UTC)")
 	fmt.Println("  --locale       Database locale (default: en_US.utf8)")
 	fmt.Println("  --network      Docker network to join (can be specified multiple times)")
+	fmt.Println("  --network-create Create the --network networks that don't exist yet (bridge driver)")
 	fmt.Println("  --init-script  SQL script to run on initialization (can be specified multiple times)")
+	fmt.Println("  --init-order   Init script file names in execution order; unlisted scripts run after, alphabetically")
+	fmt.Println("  --startup-script SQL script run via psql after every create and start (can be specified multiple times)")
 	fmt.Println("  --ssl-mode     SSL mode (disable, require, verify-ca, verify-full)")
 	fmt.Println("  --ssl-cert     Path to SSL certificate")
 	fmt.Println("  --ssl-key      Path to SSL private key")
 	fmt.Println("  --ssl-root-cert Path to SSL root certificate")
+	fmt.Println("  --ssl-gen      Generate a self-signed certificate in ~/.go-db/certs/<name>/ (or <output-dir>/certs/<name>/) and enable SSL")
+	fmt.Println("  --port-range   Port range to allocate from (e.g., 6000-6100), or how many ports from --port to try (default: 100)")
+	fmt.Println("  --exact-port   Fail if --port is taken instead of moving on to the next free port")
+	fmt.Println("  --ready-cmd    Readiness command run inside the container (default: pg_isready)")
+	fmt.Println("  --pre-create-hook  Local command run before creation; failure aborts (GODB_NAME, GODB_PORT, GODB_PASSWORD, ... are set)")
+	fmt.Println("  --post-create-hook Local command run after the container is ready; failure only warns")
+	fmt.Println("  --json-logs    Emit one JSON event per line to stderr (step started/completed, ready, error)")
+	fmt.Println("  --reuse-existing Treat an existing container as success, starting it if stopped")
+	fmt.Println("  --reconcile    Reuse an existing container if it matches; report version, port, env, memory and CPU drift")
+	fmt.Println("  --force-recreate-on-config-change With --reconcile, remove and recreate a drifted container")
+	fmt.Println("  --copy-from    Clone the data volume of an existing container (stop the source first)")
+	fmt.Println("  --replica-of   Create a streaming standby of an external primary (host:port) with pg_basebackup")
+	fmt.Println("  --replication-user / --replication-password  Replication role on the primary (required with --replica-of)")
+	fmt.Println("  --with-pgbouncer Start a PgBouncer connection pool (port 6432) in front of the database")
+	fmt.Println("  --pool-mode    PgBouncer pool mode: transaction or session (default: transaction)")
+	fmt.Println("  --skip-pull    Skip pulling the image and use the local one only (for offline use)")
+	fmt.Println("  --image        Image repository to run (default: postgres), e.g. a private registry mirror")
+	fmt.Println("  --registry-auth user:password to log in to the image's registry before pulling (never printed)")
+	fmt.Println("  --health-interval     Time between docker health checks (default: 5s)")
+	fmt.Println("  --health-timeout      Time before a single health check fails (default: 5s)")
+	fmt.Println("  --health-retries      Failed checks before the container is unhealthy (default: 5)")
+	fmt.Println("  --health-start-period Startup grace period before failures count (default: 10s)")
+	fmt.Println("  --wait-for-healthy    Wait for docker's health status rather than running pg_isready; falls back without a healthcheck")
+	fmt.Println("  --connection-timeout  Deadline for each readiness probe attempt (default: 5s)")
+	fmt.Println("  --wait-timeout How long to wait for the server to accept connections (default: 30s; also for create)")
+	fmt.Println("  --log-queries  Log every statement and its duration to the container logs, see go-db logs --follow (development only)")
+	fmt.Println("  --no-fsync     fsync, full_page_writes and synchronous_commit off for fast CI databases; data loss on crash is expected")
+	fmt.Println("  --max-wal-size Set max_wal_size, e.g. 2GB (fewer checkpoint stalls under heavy writes)")
+	fmt.Println("  --checkpoint-timeout Set checkpoint_timeout, e.g. 15min (30s to 1d)")
+	fmt.Println("  --pg-param     Any postgresql.conf parameter as key=value, e.g. work_mem=16MB (can be specified multiple times)")
+	fmt.Println("  --label        Docker label as key=value, e.g. project=shop (can be specified multiple times); shown by show")
+	fmt.Println("  --no-default-db Create no extra database; connect to the built-in postgres database instead")
+	fmt.Println("  --no-name-validation Skip checking --name against docker's naming rules ([a-zA-Z0-9][a-zA-Z0-9_.-]+)")
+	fmt.Println("  --test         Disposable test database: random high port (unless --port), tmpfs data, trust auth,")
+	fmt.Println("                 removed when stopped, 15s --wait-timeout; prints only the connection string")
+	fmt.Println("                 (export DATABASE_URL=$(go-db ...))")
+	fmt.Println("  --env-file     Write PG* variables and DATABASE_URL to a .env file (appended; --force replaces existing ones)")
+	fmt.Println("  --config       Load the configuration from a YAML, TOML or JSON file (keys are the flag names); flags override it")
+	fmt.Println("  --config-json  Same as --config, for JSON files")
+	fmt.Println("  --print-config Print the resolved configuration (flags, GODB_* variables, config file) as JSON and exit")
+	fmt.Println("  --quiet        Print only a single NDJSON result line (also for create)")
+	fmt.Println("  Every option above defaults to a GODB_<FLAG> environment variable, upper-cased with")
+	fmt.Println("  dashes as underscores (e.g. GODB_VERSION, GODB_PORT, GODB_HEALTH_RETRIES); explicit flags win")
 	fmt.Println("\nManagement Commands:")
 	fmt.Println("  start <name>   Start a stopped database container")
 	fmt.Println("  stop <name>    Stop a running database container")
-	fmt.Println("  remove <name>  Remove a database container (use --force to force removal)")
-	fmt.Println("  show <name>    Show connection details for a specific container")
+	fmt.Println("  remove <name>  Remove a database container after confirming (use --force to force removal, --volume to drop its go-db volume, --yes to skip the prompt)")
+	fmt.Println("  list           List containers (--columns name,type,status,uptime,port,id,backup picks the columns)")
+	fmt.Println("                 --since-last-backup shows each database's last backup and warns when it is overdue")
+	fmt.Println("  show <name>    Show connection details for a specific container (--redact hides the password, --json prints a JSON object)")
+	fmt.Println("  inspect <name> Print the container's image, env (passwords masked), mounts, networks, ports, limits and state as JSON (--raw for docker's own output)")
+	fmt.Println("  --prefer-ipv6  Use the host's IPv6 address in the main connection string (create, create-custom, show); the other family is shown too")
+	fmt.Println("  --prefix       Container name namespace, e.g. team-a- (default: $GODB_PREFIX); works with create, start, stop, remove, list, show, tables, databases, users, metrics, connect, exec, logs and rollback")
+	fmt.Println("  --runtime      Container CLI: docker or podman (default: $GODB_RUNTIME, else docker); works with every command")
+	fmt.Println("  --verbose      Print each docker command to stderr (dimmed, passwords masked) before running it; works with every command")
+	fmt.Println("  --no-public-ip Skip the public IP lookup (cached for an hour otherwise); only the local address is shown")
+	fmt.Println("  --selector     Act on all containers with a matching label (key=value); works with start, stop, remove and list")
+	fmt.Println("  backup <name>  Dump a database (--output file, --format plain|custom|directory, --checksum writes a .sha256 file)")
+	fmt.Println("                 --jobs N dumps in parallel (directory format only)")
+	fmt.Println("                 --schema-only / --data-only dump just the schema or just the data")
+	fmt.Println("                 --compress gzips a plain dump (--compress-level 1-9, default 6; --fast uses 1 for databases over 1 GB)")
+	fmt.Println("                 --output-dir DIR puts the default <name>-<timestamp> file in DIR")
+	fmt.Println("                 --globals also dumps roles and tablespaces to <dump>-globals.sql")
+	fmt.Println("                 --schema NAME dumps only that schema (repeatable)")
+	fmt.Println("  restore <name> <file>  Load a dump (--checksum sha256:<hex> verifies the file first)")
+	fmt.Println("                 --jobs N restores in parallel (custom or directory format)")
+	fmt.Println("                 --no-owner / --no-acl skip ownership / privilege statements")
+	fmt.Println("                 --report-size prints the resulting database size")
+	fmt.Println("                 <dump>-globals.sql is applied first when present (--no-globals skips it)")
+	fmt.Println("                 --schema NAME restores only that schema (custom or directory format, repeatable)")
+	fmt.Println("  maintenance <name>  Run --vacuum, --vacuum-full, --analyze and/or --reindex")
+	fmt.Println("  logs <name>    Print container logs (--tail N lines, default 100, -1 for all; --follow streams until Ctrl-C;")
+	fmt.Println("                 --local-time shows timestamps in the host's timezone)")
+	fmt.Println("  connect <name> Open psql with the container's credentials (--detach-keys sets the detach sequence)")
+	fmt.Println("                 --command SQL runs a single statement non-interactively")
+	fmt.Println("  exec <name> -- <command>  Run a command in the container with a TTY (e.g. bash)")
+	fmt.Println("  bench <name>   Initialize and run pgbench (--clients 10, --time 30, --scale 10, --jobs N) and print the TPS")
+	fmt.Println("  tables <name>  List tables with sizes (like \\dt+); databases <name> and users <name> work like \\l and \\du")
+	fmt.Println("  metrics <name> Print pg_stat_database, pg_stat_bgwriter and connection counts for Prometheus (--json for JSON)")
+	fmt.Println("\nRun go-db <command> --help for the flags and examples of a command.")
 	fmt.Println("\nExamples:")
 	fmt.Println("  go-db create postgres mydb")
 	fmt.Println("  go-db create-custom postgres --name mydb")
+	fmt.Println("  go-db create mysql mydb")
+	fmt.Println("  go-db create mariadb mydb")
+	fmt.Println("  go-db versions postgres")
+	fmt.Println("  go-db create postgres mydb --quiet | jq .connectionString")
 	fmt.Println("  go-db start mydb")
 	fmt.Println("  go-db stop mydb")
 	fmt.Println("  go-db remove mydb --force")
+	fmt.Println("  go-db stop --selector env=dev")
 	fmt.Println("  go-db show mydb")
+	fmt.Println("  go-db show mydb --redact")
+	fmt.Println("  go-db show mydb --json | jq -r .connectionString")
+	fmt.Println("  go-db maintenance mydb --vacuum --analyze")
+	fmt.Println("  go-db tables mydb")
+	fmt.Println("  go-db metrics mydb > mydb.prom")
+	fmt.Println("  go-db connect mydb --detach-keys ctrl-x,x")
+	fmt.Println("  go-db psql mydb --command 'SELECT count(*) FROM users'")
+	fmt.Println("  go-db backup mydb --output mydb.sql --checksum")
+	fmt.Println("  go-db restore mydb mydb.sql --checksum sha256:<hex>")
+	fmt.Println("  go-db apply stack.yaml")
+	fmt.Println("  go-db upgrade mydb --version 16 --backup-dir ./backups")
+	fmt.Println("  go-db rollback mydb")
+	fmt.Println("  go-db autostop --idle 30m")
 	fmt.Println("  go-db install-docker  # Install Docker on the current system")
 }
 
+// lookupDatabaseType returns the registered database type a command names,
+// exiting with the supported types when there is none
+func lookupDatabaseType(name string) databases.DatabaseType {
+	t, err := databases.Lookup(name)
+	if err != nil {
+		fmt.Printf("%s Error: %v\n", utils.ErrColor("✘"), err)
+		os.Exit(1)
+	}
+	return t
+}
+
+// commandAliases maps docker-style shorthands to the commands they stand for
+var commandAliases = map[string]string{
+	"rm":        "remove",
+	"ls":        "list",
+	"ps":        "list",
+	"new":       "create",
+	"log":       "logs",
+	"psql":      "connect",
+	"update":    "upgrade",
+	"destroy":   "down",
+	"--version": "version",
+}
+
+// parseArgs parses the flags in args, allowing positional arguments to appear
+// before, between or after the flags, and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseNameAndFlags parses the flags of a command that takes an optional
+// container name, accepting the name either before or after the flags
+func parseNameAndFlags(fs *flag.FlagSet, args []string) string {
+	if positional := parseArgs(fs, args); len(positional) > 0 {
+		return positional[0]
+	}
+	return ""
+}
+
+// containerTargets resolves the containers a management command acts on,
+// either the named container (within the active prefix) or all containers
+// matching the label selector
+func containerTargets(name, selector, prefix string) []string {
+	if selector == "" {
+		if name == "" {
+			printUsage()
+			os.Exit(1)
+		}
+		return []string{postgres.WithPrefix(prefix, name)}
+	}
+
+	names, err := postgres.SelectContainers(selector)
+	if err != nil {
+		fmt.Printf("%s Error selecting containers: %v\n", utils.ErrColor("✘"), err)
+		os.Exit(1)
+	}
+	return names
+}
+
+// confirmRemoval asks before remove deletes a container, saying what else
+// goes with it. Without a terminal the answer is no unless --yes was given.
+func confirmRemoval(name string, force, volume, yes bool) (bool, error) {
+	if yes {
+		return true, nil
+	}
+	question := "Remove container " + name
+	if force {
+		question += ", killing it if it is running"
+	}
+	if volume {
+		question += ", and its data volume"
+	}
+	ok, err := utils.Confirm(question + "?")
+	if err == utils.ErrNotInteractive {
+		return false, fmt.Errorf("refusing to remove %s without confirmation; pass --yes", name)
+	}
+	return ok, err
+}
+
+// createPostgres creates a PostgreSQL container and, in quiet mode, prints
+// the result as a single NDJSON line on stdout with everything else suppressed
+func createPostgres(cfg *postgres.Config, quiet bool) {
+	if quiet {
+		utils.Output = io.Discard
+	}
+
+	if err := postgres.CreateWithConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Error creating PostgreSQL database: %v\n", utils.ErrColor("✘"), err)
+		os.Exit(1)
+	}
+
+	if quiet {
+		if err := json.NewEncoder(os.Stdout).Encode(postgres.ConnectionDetails(cfg)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error writing result: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+	}
+}
+
+// createTestPostgres creates a disposable test database and prints only its
+// connection string, e.g. for export DATABASE_URL=$(go-db create-custom ...)
+func createTestPostgres(cfg *postgres.Config) {
+	utils.Output = io.Discard
+
+	if err := postgres.CreateWithConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Error creating PostgreSQL database: %v\n", utils.ErrColor("✘"), err)
+		os.Exit(1)
+	}
+	fmt.Println(postgres.ConnectionDetails(cfg).ConnectionString)
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
@@ -64,37 +325,88 @@ func main() {
 	}
 
 	command := strings.ToLower(os.Args[1])
+	if alias, ok := commandAliases[command]; ok {
+		command = alias
+	}
+
+	if err := utils.InitRuntime(); err != nil {
+		fmt.Printf("%s Error: %v\n", utils.ErrColor("✘"), err)
+		os.Exit(1)
+	}
 
 	// Initialize flags
 	postgresFlags := flags.NewPostgresFlags()
+	mysqlFlags := flags.NewMySQLFlags()
+	mariadbFlags := flags.NewMariaDBFlags()
+	stackFlags := flags.NewStackFlags()
+	versionFlags := flags.NewVersionFlags()
 
 	// Handle different commands
 	switch command {
+	case "version":
+		versionFlags.VersionFlags.Parse(os.Args[2:])
+		info := version.Get()
+		if *versionFlags.JSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(info)
+			return
+		}
+		fmt.Println(info)
+		return
+
 	case "install-docker":
 		if err := system.InstallDocker(); err != nil {
-			fmt.Printf("Error installing Docker: %v\n", err)
+			fmt.Printf("%s Error installing Docker: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
 		fmt.Println("Docker installed successfully!")
 		return
 
 	case "create":
-		if len(os.Args) < 4 {
+		if len(os.Args) < 3 {
 			fmt.Printf("%s Error: create command requires a database type and name\n", utils.ErrColor("✘"))
 			fmt.Printf("%s Example: go-db create postgres mydb\n", utils.Info("→"))
 			os.Exit(1)
 		}
-		dbType := strings.ToLower(os.Args[2])
-		name := os.Args[3]
-		switch dbType {
-		case "postgres":
-			if err := postgres.Create(name); err != nil {
-				fmt.Printf("Error creating PostgreSQL database: %v\n", err)
+		dbType := lookupDatabaseType(os.Args[2])
+		switch dbType.Name {
+		case databases.Postgres.Name:
+			name := parseNameAndFlags(postgresFlags.CreateFlags, os.Args[3:])
+			if name == "" {
+				fmt.Printf("%s Error: create command requires a database type and name\n", utils.ErrColor("✘"))
+				fmt.Printf("%s Example: go-db create postgres mydb\n", utils.Info("→"))
+				os.Exit(1)
+			}
+			cfg := postgres.DefaultConfig(name)
+			cfg.Prefix = *postgresFlags.Prefix
+			cfg.EnvFile = *postgresFlags.EnvFile
+			cfg.ReadinessTimeout = *postgresFlags.WaitTimeout
+			cfg.PreferIPv6 = *postgresFlags.PreferIPv6
+			cfg.Force = *postgresFlags.Force
+			createPostgres(cfg, *postgresFlags.Quiet)
+		case databases.MySQL.Name:
+			name := parseNameAndFlags(mysqlFlags.CreateFlags, os.Args[3:])
+			if name == "" {
+				fmt.Printf("%s Error: create command requires a database type and name\n", utils.ErrColor("✘"))
+				fmt.Printf("%s Example: go-db create mysql mydb\n", utils.Info("→"))
+				os.Exit(1)
+			}
+			if err := mysql.Create(name); err != nil {
+				fmt.Printf("%s Error creating MySQL database: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		case databases.MariaDB.Name:
+			name := parseNameAndFlags(mariadbFlags.CreateFlags, os.Args[3:])
+			if name == "" {
+				fmt.Printf("%s Error: create command requires a database type and name\n", utils.ErrColor("✘"))
+				fmt.Printf("%s Example: go-db create mariadb mydb\n", utils.Info("→"))
+				os.Exit(1)
+			}
+			if err := mariadb.Create(name); err != nil {
+				fmt.Printf("%s Error creating MariaDB database: %v\n", utils.ErrColor("✘"), err)
 				os.Exit(1)
 			}
-		default:
-			fmt.Printf("Unsupported database type: %s\n", dbType)
-			os.Exit(1)
 		}
 
 	case "create-custom":
@@ -102,70 +414,441 @@ func main() {
 			printUsage()
 			os.Exit(1)
 		}
-		dbType := strings.ToLower(os.Args[2])
-		switch dbType {
-		case "postgres":
+		dbType := lookupDatabaseType(os.Args[2])
+		switch dbType.Name {
+		case databases.Postgres.Name:
 			postgresFlags.CustomFlags.Parse(os.Args[3:])
 			if *postgresFlags.Name == "" {
 				fmt.Printf("%s Error: --name is required for create-custom\n", utils.ErrColor("✘"))
 				fmt.Printf("%s Example: go-db create-custom postgres --name mydb\n", utils.Info("→"))
 				os.Exit(1)
 			}
-			cfg := postgresFlags.BuildConfig()
-			if err := postgres.CreateWithConfig(cfg); err != nil {
-				fmt.Printf("Error creating PostgreSQL database: %v\n", err)
+			cfg, err := postgresFlags.BuildConfig()
+			if err != nil {
+				fmt.Printf("%s Error: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+			if *postgresFlags.PrintConfig {
+				if err := postgres.PrintConfig(os.Stdout, cfg); err != nil {
+					fmt.Printf("%s Error printing configuration: %v\n", utils.ErrColor("✘"), err)
+					os.Exit(1)
+				}
+				break
+			}
+			if cfg.TestMode {
+				createTestPostgres(cfg)
+				break
+			}
+			createPostgres(cfg, *postgresFlags.Quiet)
+		case databases.MySQL.Name:
+			mysqlFlags.CustomFlags.Parse(os.Args[3:])
+			if *mysqlFlags.Name == "" {
+				fmt.Printf("%s Error: --name is required for create-custom\n", utils.ErrColor("✘"))
+				fmt.Printf("%s Example: go-db create-custom mysql --name mydb\n", utils.Info("→"))
+				os.Exit(1)
+			}
+			if err := mysql.CreateWithConfig(mysqlFlags.BuildConfig()); err != nil {
+				fmt.Printf("%s Error creating MySQL database: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		case databases.MariaDB.Name:
+			mariadbFlags.CustomFlags.Parse(os.Args[3:])
+			if *mariadbFlags.Name == "" {
+				fmt.Printf("%s Error: --name is required for create-custom\n", utils.ErrColor("✘"))
+				fmt.Printf("%s Example: go-db create-custom mariadb --name mydb\n", utils.Info("→"))
+				os.Exit(1)
+			}
+			if err := mariadb.CreateWithConfig(mariadbFlags.BuildConfig()); err != nil {
+				fmt.Printf("%s Error creating MariaDB database: %v\n", utils.ErrColor("✘"), err)
 				os.Exit(1)
 			}
-		default:
-			fmt.Printf("Unsupported database type: %s\n", dbType)
-			os.Exit(1)
 		}
 
-	case "start":
+	case "versions":
 		if len(os.Args) < 3 {
 			printUsage()
 			os.Exit(1)
 		}
-		if err := postgres.Start(os.Args[2]); err != nil {
-			fmt.Printf("Error starting container: %v\n", err)
+		dbType := lookupDatabaseType(os.Args[2])
+		switch dbType.Name {
+		case databases.Postgres.Name:
+			if err := postgres.Versions(); err != nil {
+				fmt.Printf("%s Error listing versions: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Printf("%s Error: versions is not supported for %s yet\n", utils.ErrColor("✘"), dbType.DisplayName)
 			os.Exit(1)
 		}
 
+	case "start":
+		name := parseNameAndFlags(postgresFlags.StartFlags, os.Args[2:])
+		for _, n := range containerTargets(name, *postgresFlags.Selector, *postgresFlags.Prefix) {
+			start := postgres.Start
+			if mysql.IsMySQL(n) {
+				start = mysql.Start
+			} else if mariadb.IsMariaDB(n) {
+				start = mariadb.Start
+			}
+			if err := start(n); err != nil {
+				fmt.Printf("%s Error starting container: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		}
+
 	case "stop":
-		if len(os.Args) < 3 {
-			printUsage()
+		name := parseNameAndFlags(postgresFlags.StopFlags, os.Args[2:])
+		for _, n := range containerTargets(name, *postgresFlags.Selector, *postgresFlags.Prefix) {
+			stop := postgres.Stop
+			if mysql.IsMySQL(n) {
+				stop = mysql.Stop
+			} else if mariadb.IsMariaDB(n) {
+				stop = mariadb.Stop
+			}
+			if err := stop(n); err != nil {
+				fmt.Printf("%s Error stopping container: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		}
+
+	case "remove":
+		name := parseNameAndFlags(postgresFlags.RemoveFlags, os.Args[2:])
+		for _, n := range containerTargets(name, *postgresFlags.Selector, *postgresFlags.Prefix) {
+			remove := postgres.Remove
+			if mysql.IsMySQL(n) {
+				remove = mysql.Remove
+			} else if mariadb.IsMariaDB(n) {
+				remove = mariadb.Remove
+			} else if *postgresFlags.RemoveVolume {
+				remove = postgres.RemoveWithVolume
+			}
+			ok, err := confirmRemoval(n, *postgresFlags.ForceRemove, *postgresFlags.RemoveVolume, *postgresFlags.RemoveYes)
+			if err != nil {
+				fmt.Printf("%s Error removing container: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Printf("%s Kept %s\n", utils.Info("ℹ"), n)
+				continue
+			}
+			if err := remove(n, *postgresFlags.ForceRemove); err != nil {
+				fmt.Printf("%s Error removing container: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+		}
+
+	case "prune":
+		postgresFlags.PruneFlags.Parse(os.Args[2:])
+		if err := postgres.PruneVolumes(*postgresFlags.CleanupVolumes, *postgresFlags.PruneYes); err != nil {
+			fmt.Printf("%s Error pruning volumes: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "list":
+		// Without a type, every container go-db created is listed
+		dbType := parseNameAndFlags(postgresFlags.ListFlags, os.Args[2:])
+		opts := postgresFlags.BuildListOptions()
+		list := func() error { return postgres.List(opts) }
+		if dbType == "" {
+			opts.All = true
+		} else {
+			switch lookupDatabaseType(dbType).Name {
+			case databases.MySQL.Name:
+				list = mysql.List
+			case databases.MariaDB.Name:
+				list = mariadb.List
+			}
+		}
+		if err := list(); err != nil {
+			fmt.Printf("%s Error listing containers: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
-		if err := postgres.Stop(os.Args[2]); err != nil {
-			fmt.Printf("Error stopping container: %v\n", err)
+
+	case "backup":
+		name := parseNameAndFlags(postgresFlags.BackupFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: backup command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db backup mydb --output mydb.sql\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Backup(name, postgresFlags.BuildBackupOptions()); err != nil {
+			fmt.Printf("%s Error backing up database: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
 
-	case "remove":
-		if len(os.Args) < 3 {
-			printUsage()
+	case "restore":
+		args := parseArgs(postgresFlags.RestoreFlags, os.Args[2:])
+		opts := postgresFlags.BuildRestoreOptions()
+		if len(args) > 1 && opts.Input == "" {
+			opts.Input = args[1]
+		}
+		if len(args) == 0 || opts.Input == "" {
+			fmt.Printf("%s Error: restore command requires a container name and a dump file\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db restore mydb mydb.sql\n", utils.Info("→"))
 			os.Exit(1)
 		}
-		postgresFlags.RemoveFlags.Parse(os.Args[3:])
-		if err := postgres.Remove(os.Args[2], *postgresFlags.ForceRemove); err != nil {
-			fmt.Printf("Error removing container: %v\n", err)
+		if err := postgres.Restore(args[0], opts); err != nil {
+			fmt.Printf("%s Error restoring database: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
 
-	case "list":
-		if err := postgres.List(); err != nil {
-			fmt.Printf("Error listing containers: %v\n", err)
+	case "validate":
+		path := parseNameAndFlags(stackFlags.ValidateFlags, os.Args[2:])
+		configPath, err := stackFlags.ConfigPath()
+		if err != nil {
+			fmt.Printf("%s Error: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+		validate := stack.Validate
+		switch {
+		case configPath != "" && path != "":
+			fmt.Printf("%s Error: validate checks either a stack file or a config file, not both\n", utils.ErrColor("✘"))
+			os.Exit(1)
+		case configPath != "":
+			path, validate = configPath, stack.ValidateConfig
+		case path == "":
+			fmt.Printf("%s Error: validate command requires a stack file or --config\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db validate stack.yaml\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := validate(path); err != nil {
+			fmt.Printf("%s Error: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "apply", "down":
+		fs, run, verb := stackFlags.ApplyFlags, stack.Apply, "applying"
+		if command == "down" {
+			fs, verb = stackFlags.DownFlags, "taking down"
+			run = func(path string) error { return stack.Down(path, stackFlags.BuildDownOptions()) }
+		}
+		path := parseNameAndFlags(fs, os.Args[2:])
+		if path == "" {
+			fmt.Printf("%s Error: %s command requires a stack file\n", utils.ErrColor("✘"), command)
+			fmt.Printf("%s Example: go-db %s stack.yaml\n", utils.Info("→"), command)
+			os.Exit(1)
+		}
+		if err := run(path); err != nil {
+			fmt.Printf("%s Error %s stack: %v\n", utils.ErrColor("✘"), verb, err)
+			os.Exit(1)
+		}
+
+	case "health":
+		name := parseNameAndFlags(postgresFlags.HealthFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: health command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db health mydb --timeout 30s\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		healthy, err := postgres.HealthWithTimeout(postgres.WithPrefix(*postgresFlags.Prefix, name), *postgresFlags.HealthCheckTimeout)
+		if err != nil {
+			fmt.Printf("%s Error checking health: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+		if !healthy {
+			os.Exit(1)
+		}
+
+	case "clone":
+		names := parseArgs(postgresFlags.CloneFlags, os.Args[2:])
+		if len(names) != 2 {
+			fmt.Printf("%s Error: clone command requires a source container and a new name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db clone mydb mydb-fixture\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		prefix := *postgresFlags.Prefix
+		if err := postgres.Clone(postgres.WithPrefix(prefix, names[0]), postgres.WithPrefix(prefix, names[1])); err != nil {
+			fmt.Printf("%s Error cloning database: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "rename":
+		names := parseArgs(postgresFlags.RenameFlags, os.Args[2:])
+		if len(names) != 2 {
+			fmt.Printf("%s Error: rename command requires the current and the new container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db rename pg1 orders-db\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		prefix := *postgresFlags.Prefix
+		if err := postgres.Rename(postgres.WithPrefix(prefix, names[0]), postgres.WithPrefix(prefix, names[1])); err != nil {
+			fmt.Printf("%s Error renaming container: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "autostop":
+		parseArgs(postgresFlags.AutostopFlags, os.Args[2:])
+		if err := postgres.Autostop(postgresFlags.BuildAutostopOptions()); err != nil {
+			fmt.Printf("%s Error watching containers: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "upgrade":
+		name := parseNameAndFlags(postgresFlags.UpgradeFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: upgrade command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db upgrade mydb --version 16\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Upgrade(postgres.WithPrefix(*postgresFlags.Prefix, name), postgresFlags.BuildUpgradeOptions()); err != nil {
+			fmt.Printf("%s Error upgrading container: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "rollback":
+		name := parseNameAndFlags(postgresFlags.RollbackFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: rollback command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db rollback mydb\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Rollback(postgres.WithPrefix(*postgresFlags.Prefix, name)); err != nil {
+			fmt.Printf("%s Error rolling back container: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "maintenance":
+		name := parseNameAndFlags(postgresFlags.MaintenanceFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: maintenance command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db maintenance mydb --vacuum --analyze\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Maintenance(name, postgresFlags.BuildMaintenanceOptions()); err != nil {
+			fmt.Printf("%s Error running maintenance: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "bench":
+		name := parseNameAndFlags(postgresFlags.BenchFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: bench command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db bench mydb --clients 10 --time 30\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Bench(name, postgresFlags.BuildBenchOptions()); err != nil {
+			fmt.Printf("%s Error running benchmark: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "tables", "databases", "users":
+		name := parseNameAndFlags(postgresFlags.CatalogFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: %s command requires a container name\n", utils.ErrColor("✘"), command)
+			fmt.Printf("%s Example: go-db %s mydb\n", utils.Info("→"), command)
+			os.Exit(1)
+		}
+		inspect := map[string]func(string) error{
+			"tables":    postgres.Tables,
+			"databases": postgres.Databases,
+			"users":     postgres.Users,
+		}[command]
+		if err := inspect(postgres.WithPrefix(*postgresFlags.Prefix, name)); err != nil {
+			fmt.Printf("%s Error listing %s: %v\n", utils.ErrColor("✘"), command, err)
+			os.Exit(1)
+		}
+
+	case "inspect":
+		name := parseNameAndFlags(postgresFlags.InspectFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: inspect command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db inspect mydb\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.PrintInspect(os.Stdout, postgres.WithPrefix(*postgresFlags.Prefix, name), *postgresFlags.InspectRaw); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error inspecting container: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "metrics":
+		name := parseNameAndFlags(postgresFlags.MetricsFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: metrics command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db metrics mydb --json\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Metrics(os.Stdout, postgres.WithPrefix(*postgresFlags.Prefix, name), postgresFlags.BuildMetricsOptions()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error collecting metrics: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "connect":
+		name := parseNameAndFlags(postgresFlags.ConnectFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: connect command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db connect mydb\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Connect(postgres.WithPrefix(*postgresFlags.Prefix, name), postgresFlags.BuildConnectOptions()); err != nil {
+			fmt.Printf("%s Error connecting to database: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "exec":
+		// Everything after -- belongs to the command, so its flags are not parsed as ours
+		args, command := os.Args[2:], []string(nil)
+		for i, arg := range args {
+			if arg == "--" {
+				args, command = args[:i], args[i+1:]
+				break
+			}
+		}
+		positional := parseArgs(postgresFlags.ConnectFlags, args)
+		if len(positional) > 1 && command == nil {
+			command = positional[1:]
+		}
+		if len(positional) == 0 || len(command) == 0 {
+			fmt.Printf("%s Error: exec command requires a container name and a command\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db exec mydb -- bash\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Exec(postgres.WithPrefix(*postgresFlags.Prefix, positional[0]), command, postgresFlags.BuildConnectOptions()); err != nil {
+			fmt.Printf("%s Error running command: %v\n", utils.ErrColor("✘"), err)
+			os.Exit(1)
+		}
+
+	case "logs":
+		name := parseNameAndFlags(postgresFlags.LogsFlags, os.Args[2:])
+		if name == "" {
+			fmt.Printf("%s Error: logs command requires a container name\n", utils.ErrColor("✘"))
+			fmt.Printf("%s Example: go-db logs mydb --local-time\n", utils.Info("→"))
+			os.Exit(1)
+		}
+		if err := postgres.Logs(postgres.WithPrefix(*postgresFlags.Prefix, name), postgresFlags.BuildLogsOptions()); err != nil {
+			fmt.Printf("%s Error reading logs: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
 
 	case "show":
-		if len(os.Args) < 3 {
+		name := parseNameAndFlags(postgresFlags.ShowFlags, os.Args[2:])
+		if name == "" {
+			name = *postgresFlags.ShowContainer
+		}
+		if name == "" {
 			fmt.Printf("%s Error: show command requires a container name\n", utils.ErrColor("✘"))
 			fmt.Printf("%s Example: go-db show mydb\n", utils.Info("→"))
 			os.Exit(1)
 		}
-		if err := postgres.ShowConnectionDetails(os.Args[2]); err != nil {
-			fmt.Printf("Error showing container details: %v\n", err)
+		name = postgres.WithPrefix(*postgresFlags.Prefix, name)
+		if *postgresFlags.ShowJSON {
+			// Only the JSON object goes to stdout
+			utils.Output = io.Discard
+		}
+		if mysql.IsMySQL(name) {
+			if err := mysql.ShowConnectionDetails(name, mysql.ShowOptions{Redact: *postgresFlags.ShowRedact, JSON: *postgresFlags.ShowJSON}); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Error showing container details: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+			break
+		}
+		if mariadb.IsMariaDB(name) {
+			if err := mariadb.ShowConnectionDetails(name, mariadb.ShowOptions{Redact: *postgresFlags.ShowRedact, JSON: *postgresFlags.ShowJSON}); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Error showing container details: %v\n", utils.ErrColor("✘"), err)
+				os.Exit(1)
+			}
+			break
+		}
+		if err := postgres.ShowConnectionDetails(name, postgresFlags.BuildShowOptions()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error showing container details: %v\n", utils.ErrColor("✘"), err)
 			os.Exit(1)
 		}
 
@@ -0,0 +1,534 @@
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/awade12/go-db/src/databases"
+	dberrors "github.com/awade12/go-db/src/errors"
+	"github.com/awade12/go-db/src/utils"
+	"github.com/schollz/progressbar/v3"
+)
+
+// Colors decorate printed output only; returned errors stay plain text
+var (
+	success  = utils.Success
+	info     = utils.Info
+	warn     = utils.Warn
+	errColor = utils.ErrColor
+)
+
+// printf writes human-readable output, which is discarded in machine-readable modes
+func printf(format string, a ...interface{}) {
+	fmt.Fprintf(utils.Output, format, a...)
+}
+
+// Defaults from the mysql entry of the database type registry
+var (
+	defaultMySQLVersion = databases.MySQL.DefaultVersion
+	defaultPort         = databases.MySQL.DefaultPortString()
+)
+
+const (
+	rootUser = "root"
+	dataDir  = "/var/lib/mysql"
+)
+
+// Config holds the configuration of a MySQL container. The JSON keys match
+// the create-custom flag names, like the PostgreSQL one.
+type Config struct {
+	Version       string   `json:"version"`       // MySQL version (image tag)
+	Port          string   `json:"port"`          // host port mapped to 3306
+	Password      string   `json:"password"`      // password of Username
+	RootPassword  string   `json:"root-password"` // root password (default: Password)
+	ContainerName string   `json:"name"`          // required: name of the container
+	Username      string   `json:"user"`          // root, or an additional user created via MYSQL_USER
+	Database      string   `json:"db"`            // database created on first start
+	Volume        string   `json:"volume"`        // data volume path or name for persistence
+	Memory        string   `json:"memory"`        // memory limit
+	CPU           string   `json:"cpu"`           // CPU limit
+	Timezone      string   `json:"timezone"`      // container timezone
+	Networks      []string `json:"network"`       // docker networks to join
+}
+
+// setupStep is one stage of container creation shown in the progress bar
+type setupStep struct {
+	name string
+	fn   func() error
+}
+
+// DefaultConfig returns the configuration used by easy mode
+func DefaultConfig(name string) *Config {
+	if name == "" {
+		name = "mysql-" + time.Now().Format("20060102-150405")
+	}
+	return &Config{
+		Version:       defaultMySQLVersion,
+		Port:          defaultPort,
+		Password:      utils.GenerateSecurePassword(),
+		ContainerName: name,
+		Username:      rootUser,
+		Database:      name,
+		Timezone:      "UTC",
+	}
+}
+
+// Create sets up a new MySQL instance with the default configuration
+func Create(name string) error {
+	return CreateWithConfig(DefaultConfig(name))
+}
+
+// Validate checks the configuration before anything is created
+func (c *Config) Validate() error {
+	if c.ContainerName == "" {
+		return fmt.Errorf("container name is required")
+	}
+	if err := databases.ValidateContainerName(c.ContainerName); err != nil {
+		return err
+	}
+	if err := databases.ValidateTimezone(c.Timezone); err != nil {
+		return err
+	}
+	if c.Password == "" {
+		return fmt.Errorf("password is required")
+	}
+	return nil
+}
+
+// CreateWithConfig sets up a new MySQL instance with custom configuration
+func CreateWithConfig(cfg *Config) error {
+	if cfg == nil {
+		return fmt.Errorf("configuration cannot be nil")
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	volume, err := utils.ExpandHome(cfg.Volume)
+	if err != nil {
+		return fmt.Errorf("invalid --volume: %v", err)
+	}
+	cfg.Volume = volume
+	if cfg.Username == "" {
+		cfg.Username = rootUser
+	}
+	if cfg.RootPassword == "" {
+		cfg.RootPassword = cfg.Password
+	}
+
+	printf("%s Starting MySQL setup for %s...\n", info("ℹ"), cfg.ContainerName)
+
+	if err := utils.CheckRuntime(); err != nil {
+		return err
+	}
+
+	if exists, _ := containerExists(cfg.ContainerName); exists {
+		return dberrors.Newf(dberrors.ErrContainerExists, "Container %s already exists. Use 'go-db remove %s' to remove it first",
+			cfg.ContainerName, cfg.ContainerName)
+	}
+
+	// Find an available port if the default is taken
+	if cfg.Port == defaultPort {
+		port, err := databases.MySQL.FindPort(0, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to find available port: %v", err)
+		}
+		cfg.Port = fmt.Sprintf("%d", port)
+		if cfg.Port != defaultPort {
+			printf("%s Port %s is held by %s, using port %s instead\n",
+				info("ℹ"), defaultPort, databases.DescribePortHolder(databases.MySQL.DefaultPort), cfg.Port)
+		}
+	}
+
+	image := fmt.Sprintf("%s:%s", databases.MySQL.Image, cfg.Version)
+	steps := []setupStep{
+		{
+			name: "Pulling MySQL image",
+			fn: func() error {
+				// Only pull if image doesn't exist
+				if out, _ := utils.RunDocker("images", "-q", image).Output(); len(out) == 0 {
+					return utils.RunDocker("pull", image).Run()
+				}
+				return nil
+			},
+		},
+		{
+			name: "Creating container",
+			fn: func() error {
+				return utils.RunDocker(buildDockerArgs(cfg)...).Run()
+			},
+		},
+		{
+			name: "Waiting for container to be ready",
+			fn: func() error {
+				return waitForMySQL(cfg)
+			},
+		},
+	}
+
+	bar := progressbar.NewOptions(len(steps),
+		progressbar.OptionSetWriter(utils.Output),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetWidth(30),
+		progressbar.OptionSetDescription("[cyan]Setting up MySQL[reset]"),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}))
+
+	for _, step := range steps {
+		bar.Describe(fmt.Sprintf("[cyan]%s[reset]", step.name))
+		if err := step.fn(); err != nil {
+			printf("\n%s %s failed: %v\n", errColor("✘"), step.name, err)
+			return fmt.Errorf("failed during %s: %v", step.name, err)
+		}
+		bar.Add(1)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	printf("\n%s MySQL container created successfully!\n", success("✔"))
+	printConnectionDetails(cfg)
+	return nil
+}
+
+func buildDockerArgs(cfg *Config) []string {
+	args := []string{
+		"run",
+		"--name", cfg.ContainerName,
+		"-e", fmt.Sprintf("MYSQL_ROOT_PASSWORD=%s", cfg.RootPassword),
+		"-e", fmt.Sprintf("TZ=%s", cfg.Timezone),
+		"-p", databases.MySQL.PortMapping(cfg.Port),
+		"-d",
+	}
+	args = append(args, databases.MySQL.LabelArgs()...)
+	if cfg.Database != "" {
+		args = append(args, "-e", fmt.Sprintf("MYSQL_DATABASE=%s", cfg.Database))
+	}
+
+	// root always exists; any other user is created and granted the database
+	if cfg.Username != rootUser {
+		args = append(args,
+			"-e", fmt.Sprintf("MYSQL_USER=%s", cfg.Username),
+			"-e", fmt.Sprintf("MYSQL_PASSWORD=%s", cfg.Password))
+	}
+
+	if cfg.Volume != "" {
+		args = append(args, "-v", fmt.Sprintf("%s:%s", cfg.Volume, dataDir))
+	}
+	if cfg.Memory != "" {
+		args = append(args, "--memory", cfg.Memory)
+	}
+	if cfg.CPU != "" {
+		args = append(args, "--cpus", cfg.CPU)
+	}
+	for _, network := range cfg.Networks {
+		args = append(args, "--network", network)
+	}
+
+	return append(args, fmt.Sprintf("%s:%s", databases.MySQL.Image, cfg.Version))
+}
+
+// waitForMySQL polls mysqladmin ping over TCP. The image's entrypoint first
+// runs a temporary server without networking to initialize the database, so
+// pinging 127.0.0.1 only succeeds once the real server is up.
+func waitForMySQL(cfg *Config) error {
+	maxAttempts := 60
+	for i := 0; i < maxAttempts; i++ {
+		// The password goes through the environment so it never shows in the
+		// command line, ps output or --verbose
+		probe := append([]string{"exec", "-e", "MYSQL_PWD=" + cfg.RootPassword, cfg.ContainerName}, databases.MySQL.ReadyCommand...)
+		cmd := utils.RunDocker(append(probe, "-h", "127.0.0.1", "-u", rootUser)...)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("timeout waiting for MySQL to be ready")
+}
+
+// IsMySQL reports whether a container runs a MySQL image, so the management
+// commands can route it to this package
+func IsMySQL(containerName string) bool {
+	image, err := containerImage(containerName)
+	if err != nil {
+		return false
+	}
+	if i := strings.LastIndex(image, "/"); i != -1 {
+		image = image[i+1:]
+	}
+	return image == "mysql" || strings.HasPrefix(image, "mysql:")
+}
+
+func Stop(containerName string) error {
+	if exists, running := containerExists(containerName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	} else if !running {
+		return fmt.Errorf("Container %s is already stopped", containerName)
+	}
+
+	printf("%s Stopping container %s...\n", info("ℹ"), containerName)
+	if err := utils.RunDocker("stop", containerName).Run(); err != nil {
+		return fmt.Errorf("Failed to stop container: %v", err)
+	}
+
+	printf("%s Container %s stopped successfully\n", success("✔"), containerName)
+	return nil
+}
+
+func Start(containerName string) error {
+	if exists, running := containerExists(containerName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	} else if running {
+		return fmt.Errorf("Container %s is already running", containerName)
+	}
+
+	printf("%s Starting container %s...\n", info("ℹ"), containerName)
+	if err := utils.RunDocker("start", containerName).Run(); err != nil {
+		return fmt.Errorf("Failed to start container: %v", err)
+	}
+
+	printf("%s Container %s started successfully\n", success("✔"), containerName)
+	return nil
+}
+
+func Remove(containerName string, force bool) error {
+	if exists, _ := containerExists(containerName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	}
+
+	args := []string{"rm"}
+	if force {
+		args = append(args, "-f")
+	}
+	args = append(args, containerName)
+
+	printf("%s Removing container %s...\n", info("ℹ"), containerName)
+	if err := utils.RunDocker(args...).Run(); err != nil {
+		return fmt.Errorf("Failed to remove container: %v", err)
+	}
+
+	printf("%s Container %s removed successfully\n", success("✔"), containerName)
+	return nil
+}
+
+func containerExists(name string) (exists bool, running bool) {
+	out, err := utils.RunDocker("ps", "-a", "--filter", fmt.Sprintf("name=^%s$", regexp.QuoteMeta(name)), "--format", "{{.Status}}").Output()
+	if err != nil {
+		return false, false
+	}
+
+	status := strings.TrimSpace(string(out))
+	if status == "" {
+		return false, false
+	}
+	return true, strings.HasPrefix(status, "Up")
+}
+
+// List displays all MySQL containers (both running and stopped)
+func List() error {
+	printf("\n%s MySQL Containers\n", info("📦"))
+
+	// The type label matches every tag and image, which an ancestor filter doesn't
+	output, err := utils.RunDocker("ps", "-a", "--filter", databases.MySQL.LabelFilter(),
+		"--format", "{{.Names}}\t{{.Status}}\t{{.Ports}}\t{{.ID}}").Output()
+	if err != nil {
+		return fmt.Errorf("Failed to list containers: %v", err)
+	}
+	if len(output) == 0 {
+		printf("\n  %s No MySQL containers found\n\n", warn("⚠"))
+		return nil
+	}
+
+	printf("\n  %-20s %-22s %-8s %-12s\n", "NAME", "STATUS", "PORT", "ID")
+	printf("  %s\n", strings.Repeat("─", 66))
+
+	for _, container := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(container, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		name, status, ports := fields[0], fields[1], fields[2]
+		id := ""
+		if len(fields) > 3 && len(fields[3]) >= 12 {
+			id = fields[3][:12]
+		}
+
+		// Extract just the host port for cleaner display
+		port := "N/A"
+		if portMatch := strings.Split(ports, ":"); len(portMatch) > 1 {
+			port = strings.Split(portMatch[1], "-")[0]
+		}
+
+		statusColor, statusSymbol, shortStatus := warn, "🔴", "Stopped ⏹️"
+		if strings.HasPrefix(status, "Up") {
+			statusColor, statusSymbol = success, "🟢"
+			shortStatus = "Running ⏵️ " + strings.TrimPrefix(status, "Up ")
+		}
+
+		printf("  %s %s %s %-8s %-12s\n",
+			info(fmt.Sprintf("%-20s", name)),
+			statusSymbol, statusColor(fmt.Sprintf("%-19s", shortStatus)),
+			port, id)
+	}
+	printf("\n")
+	return nil
+}
+
+// ShowOptions controls how ShowConnectionDetails presents a container
+type ShowOptions struct {
+	Redact bool // replace the password with <redacted> everywhere it appears
+	JSON   bool // print the details as a JSON object instead of text
+}
+
+// ShowInfo is the machine-readable form of show's output, with the same keys
+// as the PostgreSQL one
+type ShowInfo struct {
+	Name                     string `json:"name"`
+	Host                     string `json:"host"`
+	Port                     string `json:"port"`
+	User                     string `json:"user"`
+	Password                 string `json:"password"`
+	Database                 string `json:"database"`
+	ConnectionString         string `json:"connectionString"`
+	ExternalConnectionString string `json:"externalConnectionString,omitempty"`
+}
+
+// ShowConnectionDetails displays connection information for a specific container
+func ShowConnectionDetails(containerName string, opts ShowOptions) error {
+	if exists, _ := containerExists(containerName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	}
+
+	cfg, err := inspectConfig(containerName)
+	if err != nil {
+		return fmt.Errorf("Failed to get container details: %v", err)
+	}
+	if opts.Redact {
+		cfg.Password = "<redacted>"
+	}
+
+	if opts.JSON {
+		host, err := utils.GetOutboundIP()
+		if err != nil {
+			host = "localhost"
+		}
+		details := ShowInfo{
+			Name:             cfg.ContainerName,
+			Host:             host,
+			Port:             cfg.Port,
+			User:             cfg.Username,
+			Password:         cfg.Password,
+			Database:         cfg.Database,
+			ConnectionString: ConnectionString(cfg, host),
+		}
+		if publicIP, err := utils.GetPublicIP(); err == nil && publicIP != host {
+			details.ExternalConnectionString = ConnectionString(cfg, publicIP)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(details)
+	}
+
+	printConnectionDetails(cfg)
+	return nil
+}
+
+// ConnectionString returns the connection URL of a container, in the same
+// form as the PostgreSQL one
+func ConnectionString(cfg *Config, host string) string {
+	return fmt.Sprintf("mysql://%s:%s@%s/%s", cfg.Username, cfg.Password, net.JoinHostPort(host, cfg.Port), cfg.Database)
+}
+
+func printConnectionDetails(cfg *Config) {
+	serverIP, err := utils.GetOutboundIP()
+	if err != nil {
+		serverIP = "localhost" // Fallback to localhost if IP detection fails
+		printf("%s Warning: Could not detect server IP, using localhost\n", warn("⚠"))
+	}
+
+	printf("\n%s Connection Details:\n", info("ℹ"))
+	printf("  %s Host: %s\n", info("→"), serverIP)
+	printf("  %s Port: %s\n", info("→"), cfg.Port)
+	printf("  %s User: %s\n", info("→"), cfg.Username)
+	printf("  %s Password: %s\n", info("→"), cfg.Password)
+	printf("  %s Database: %s\n", info("→"), cfg.Database)
+	if cfg.Volume != "" {
+		printf("  %s Data Volume: %s\n", info("→"), cfg.Volume)
+	}
+
+	printf("\n%s Management Commands:\n", info("ℹ"))
+	printf("  %s Stop:    go-db stop %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Start:   go-db start %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Remove:  go-db remove %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Logs:    go-db logs %s\n", info("→"), cfg.ContainerName)
+
+	printf("\n%s Connection String:\n", info("ℹ"))
+	printf("  %s %s\n", info("→"), ConnectionString(cfg, serverIP))
+
+	// Try to get public IP for external access
+	publicIP, err := utils.GetPublicIP()
+	if err == nil && publicIP != serverIP {
+		printf("\n%s External Connection String:\n", info("ℹ"))
+		printf("  %s %s\n", info("→"), ConnectionString(cfg, publicIP))
+	}
+}
+
+// containerImage returns the image reference a container was created from
+func containerImage(containerName string) (string, error) {
+	output, err := utils.RunDocker("inspect", "--format", "{{.Config.Image}}", containerName).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get container image: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// inspectConfig reconstructs the connection-relevant parts of a Config from an existing container
+func inspectConfig(containerName string) (*Config, error) {
+	output, err := utils.RunDocker("inspect", "--format",
+		"{{range .Config.Env}}{{.}}{{println}}{{end}}", containerName).Output()
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			env[key] = value
+		}
+	}
+
+	port, err := utils.RunDocker("inspect", "--format",
+		"{{range $p, $conf := .HostConfig.PortBindings}}{{if eq $p \"3306/tcp\"}}{{range $conf}}{{.HostPort}}{{end}}{{end}}{{end}}",
+		containerName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port mapping: %v", err)
+	}
+
+	cfg := &Config{
+		ContainerName: containerName,
+		Port:          strings.TrimSpace(string(port)),
+		Username:      rootUser,
+		Password:      env["MYSQL_ROOT_PASSWORD"],
+		RootPassword:  env["MYSQL_ROOT_PASSWORD"],
+		Database:      env["MYSQL_DATABASE"],
+		Timezone:      env["TZ"],
+	}
+	if user := env["MYSQL_USER"]; user != "" {
+		cfg.Username = user
+		cfg.Password = env["MYSQL_PASSWORD"]
+	}
+	if image, err := containerImage(containerName); err == nil {
+		if i := strings.LastIndex(image, ":"); i != -1 {
+			cfg.Version = image[i+1:]
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,61 @@
+package databases
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// containerNamePattern is the naming rule docker enforces for container names
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ValidateContainerName checks a name against docker's container naming rules
+// before any docker command runs, highlighting the first offending character
+// and suggesting a name that would be accepted
+func ValidateContainerName(name string) error {
+	if containerNamePattern.MatchString(name) {
+		return nil
+	}
+
+	const rule = "names must start with a letter or digit, contain only letters, digits, _, . and -, and be at least 2 characters long"
+	hint := ""
+	if suggestion := SuggestContainerName(name); suggestion != "" {
+		hint = fmt.Sprintf("; try %q", suggestion)
+	}
+	position := 0
+	for i, r := range name {
+		position++
+		valid := r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		if i > 0 {
+			valid = valid || r == '_' || r == '.' || r == '-'
+		}
+		if !valid {
+			return fmt.Errorf("invalid container name %q: character %q at position %d is not allowed (%s): %s[%c]%s%s",
+				name, r, position, rule, name[:i], r, name[i+len(string(r)):], hint)
+		}
+	}
+	return fmt.Errorf("invalid container name %q: %s%s", name, rule, hint)
+}
+
+// SuggestContainerName turns a name into one docker accepts: disallowed
+// characters become -, leading punctuation is dropped and a too-short name is
+// padded. It returns "" when nothing usable is left.
+func SuggestContainerName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'), r == '_', r == '.':
+			b.WriteRune(r)
+		case !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	suggestion := strings.TrimRight(strings.TrimLeft(b.String(), "_.-"), "-")
+	switch {
+	case suggestion == "":
+		return ""
+	case len(suggestion) < 2:
+		suggestion += "-db"
+	}
+	return suggestion
+}
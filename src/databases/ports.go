@@ -0,0 +1,72 @@
+package databases
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// DescribePortHolder says what holds a host port, for the messages shown when
+// a requested port is taken: a go-db container (with the command to see its
+// details), another docker container, or a process outside docker
+func DescribePortHolder(port int) string {
+	name, managed, err := portContainer(port)
+	switch {
+	case err != nil:
+		return "another process"
+	case name == "":
+		return "a process outside docker"
+	case managed:
+		return fmt.Sprintf("go-db container %s (see go-db show %s)", name, name)
+	default:
+		return "docker container " + name
+	}
+}
+
+// portContainer returns the running container publishing a host port, if any
+func portContainer(port int) (name string, managed bool, err error) {
+	output, err := utils.RunDocker("ps", "--format",
+		fmt.Sprintf("{{.Names}}\t{{.Ports}}\t{{.Label %q}}", ManagedLabel)).Output()
+	if err != nil {
+		return "", false, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		if publishes(fields[1], port) {
+			return fields[0], len(fields) > 2 && fields[2] == "true", nil
+		}
+	}
+	return "", false, nil
+}
+
+// publishes reports whether docker ps's ports column, such as
+// "0.0.0.0:5432->5432/tcp, :::5432->5432/tcp", maps the host port
+func publishes(ports string, port int) bool {
+	for _, mapping := range strings.Split(ports, ",") {
+		host, _, found := strings.Cut(strings.TrimSpace(mapping), "->")
+		if !found {
+			continue
+		}
+		host = host[strings.LastIndex(host, ":")+1:]
+		first, last, isRange := strings.Cut(host, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				continue
+			}
+		}
+		if port >= start && port <= end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AutostopOptions controls which containers Autostop watches and when it stops them
+type AutostopOptions struct {
+	Idle     time.Duration // stop a container after this long without client connections
+	Interval time.Duration // how often pg_stat_activity is polled
+	Selector string        // only watch containers with this label (key=value)
+	Prefix   string        // only watch containers in this namespace
+}
+
+// activeConnectionsQuery counts client connections other than the polling one
+const activeConnectionsQuery = `SELECT count(*) FROM pg_stat_activity
+	WHERE backend_type = 'client backend' AND pid <> pg_backend_pid()`
+
+// Autostop watches the running PostgreSQL containers in the foreground and
+// stops each one that has had no client connections for opts.Idle. It runs
+// until interrupted with Ctrl-C; starting the containers again is up to the
+// user.
+func Autostop(opts AutostopOptions) error {
+	if opts.Idle <= 0 {
+		return fmt.Errorf("--idle must be a positive duration, e.g. 30m")
+	}
+	if opts.Interval <= 0 {
+		return fmt.Errorf("--interval must be a positive duration, e.g. 1m")
+	}
+	if opts.Selector != "" {
+		if err := validateSelector(opts.Selector); err != nil {
+			return err
+		}
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	printf("%s Stopping PostgreSQL containers idle for %s (checking every %s, Ctrl-C to quit)\n",
+		info("ℹ"), opts.Idle, opts.Interval)
+
+	lastActive := make(map[string]time.Time)
+	configs := make(map[string]*Config)
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := autostopPass(opts, lastActive, configs); err != nil {
+			printf("%s Warning: %v\n", warn("⚠"), err)
+		}
+
+		select {
+		case <-interrupted:
+			printf("\n%s Stopped watching\n", info("ℹ"))
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// autostopPass polls every watched container once and stops the idle ones.
+// A container is considered active when it's first seen and whenever its
+// connections can't be counted, so a slow start never gets it stopped.
+func autostopPass(opts AutostopOptions, lastActive map[string]time.Time, configs map[string]*Config) error {
+	names, err := runningPostgresContainers(opts.Selector, opts.Prefix)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	running := make(map[string]bool, len(names))
+	for _, name := range names {
+		running[name] = true
+		if _, seen := lastActive[name]; !seen {
+			printf("%s Watching %s\n", info("ℹ"), name)
+			lastActive[name] = now
+		}
+
+		cfg, ok := configs[name]
+		if !ok {
+			if cfg, err = inspectConfig(name); err != nil {
+				printf("%s Warning: Could not inspect %s: %v\n", warn("⚠"), name, err)
+				continue
+			}
+			configs[name] = cfg
+		}
+
+		count, err := psqlQuery(cfg, activeConnectionsQuery)
+		if err != nil {
+			lastActive[name] = now
+			continue
+		}
+		if n, _ := strconv.Atoi(count); n > 0 {
+			lastActive[name] = now
+			continue
+		}
+
+		if idle := now.Sub(lastActive[name]); idle >= opts.Idle {
+			printf("%s %s has had no connections for %s\n", info("ℹ"), name, idle.Round(time.Second))
+			if err := Stop(name); err != nil {
+				printf("%s Warning: %v\n", warn("⚠"), err)
+				continue
+			}
+			running[name] = false
+		}
+	}
+
+	// Forget containers that were stopped or removed, so a restarted one
+	// gets a fresh idle period
+	for name := range lastActive {
+		if !running[name] {
+			delete(lastActive, name)
+			delete(configs, name)
+		}
+	}
+	return nil
+}
+
+// runningPostgresContainers returns the names of the running go-db managed
+// containers whose image is a PostgreSQL image; containers go-db didn't create
+// are never stopped
+func runningPostgresContainers(selector, prefix string) ([]string, error) {
+	args := []string{"ps", "--filter", "label=" + managedLabel + "=true"}
+	if selector != "" {
+		args = append(args, "--filter", "label="+selector)
+	}
+	if prefix != "" {
+		args = append(args, "--filter", "name=^"+regexp.QuoteMeta(prefix))
+	}
+	output, err := dockerClient.Output(append(args, "--format", "{{.Names}}\t{{.Image}}")...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list containers: %v", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name, image, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		// postgres:16, registry.example.com/library/postgres:16-alpine, ...
+		repo := image
+		if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+			repo = repo[:i]
+		}
+		if repo[strings.LastIndex(repo, "/")+1:] == "postgres" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,610 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// BackupOptions controls how Backup dumps a database
+type BackupOptions struct {
+	Output     string   // destination file (default: <name>-<timestamp>.sql or .dump)
+	Format     string   // dump format (plain, custom, directory)
+	Checksum   bool     // write a <output>.sha256 sidecar next to the dump
+	Jobs       int      // parallel dump jobs, directory format only
+	SchemaOnly bool     // dump only the schema, no data
+	DataOnly   bool     // dump only the data, no schema
+	OutputDir  string   // directory for the default output file (default: output_dir setting or .)
+	Globals    bool     // also dump roles and tablespaces with pg_dumpall --globals-only
+	Schemas    []string // dump only these schemas (pg_dump -n)
+	Compress   bool     // gzip a plain dump while streaming it
+	Level      int      // gzip compression level, 1 (fastest) to 9 (smallest)
+	Fast       bool     // prefer speed: use gzip level 1 for large databases
+}
+
+// Gzip levels of compressed backups
+const (
+	defaultCompressLevel = 6
+	// largeDatabaseBytes is the size above which --fast drops to gzip level 1
+	largeDatabaseBytes = 1 << 30
+)
+
+// RestoreOptions controls how Restore loads a dump
+type RestoreOptions struct {
+	Input      string   // dump file to restore
+	Checksum   string   // expected checksum in the form sha256:<hex>
+	Jobs       int      // parallel restore jobs, custom and directory formats only
+	NoOwner    bool     // skip ownership statements (for restoring under a different role)
+	NoACL      bool     // skip GRANT/REVOKE privilege statements
+	ReportSize bool     // print the database size after the restore
+	NoGlobals  bool     // don't apply the <dump>-globals.sql file even if it exists
+	Schemas    []string // restore only these schemas (pg_restore -n), custom and directory formats only
+}
+
+// Backup dumps a database with pg_dump into a local file
+func Backup(containerName string, opts BackupOptions) error {
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == "" {
+		opts.Format = "plain"
+	}
+	if opts.Format != "plain" && opts.Format != "custom" && opts.Format != "directory" {
+		return fmt.Errorf("invalid backup format %q, expected plain, custom or directory", opts.Format)
+	}
+	if opts.SchemaOnly && opts.DataOnly {
+		return fmt.Errorf("--schema-only and --data-only are mutually exclusive")
+	}
+	if opts.Jobs < 0 {
+		return fmt.Errorf("--jobs cannot be negative")
+	}
+	if opts.Jobs > 1 && opts.Format != "directory" {
+		return fmt.Errorf("parallel backups (--jobs) require --format directory")
+	}
+	if err := validateSchemas(opts.Schemas); err != nil {
+		return err
+	}
+	if opts.Level == 0 {
+		opts.Level = defaultCompressLevel
+	}
+	if opts.Level < gzip.BestSpeed || opts.Level > gzip.BestCompression {
+		return fmt.Errorf("--compress-level must be between 1 and 9")
+	}
+	if opts.Compress && opts.Format != "plain" {
+		return fmt.Errorf("--compress requires --format plain; custom archives are already compressed by pg_dump")
+	}
+	if opts.Output == "" {
+		ext := ".sql"
+		switch opts.Format {
+		case "custom":
+			ext = ".dump"
+		case "directory":
+			ext = ""
+		}
+		if opts.Compress {
+			ext += ".gz"
+		}
+		dir, err := utils.OutputDir(opts.OutputDir)
+		if err != nil {
+			return err
+		}
+		opts.Output = filepath.Join(dir, fmt.Sprintf("%s-%s%s", containerName, time.Now().Format("20060102-150405"), ext))
+	}
+
+	printf("%s Backing up database %s from %s...\n", info("ℹ"), cfg.Database, containerName)
+	start := time.Now()
+
+	if opts.Format == "directory" {
+		if err := backupDirectory(containerName, cfg, opts); err != nil {
+			return err
+		}
+		size, _ := pathSize(opts.Output)
+		printf("%s Backup written to %s (%s)\n", success("✔"), opts.Output, throughput(size, time.Since(start)))
+		recordBackup(containerName)
+		if opts.Checksum {
+			printf("%s Warning: --checksum is only supported for single-file formats\n", warn("⚠"))
+		}
+		if opts.Globals {
+			return backupGlobals(containerName, cfg, opts.Output)
+		}
+		return nil
+	}
+
+	file, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("Failed to create backup file: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	var out io.Writer = io.MultiWriter(file, hash)
+	var gz *gzip.Writer
+	if opts.Compress {
+		level := compressLevel(cfg, opts)
+		gz, _ = gzip.NewWriterLevel(out, level)
+		out = gz
+		printf("%s Compressing with gzip level %d\n", info("ℹ"), level)
+	}
+
+	err = dockerClient.Stream(nil, out, os.Stderr, append([]string{"exec", containerName}, pgDumpArgs(cfg, opts)...)...)
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		os.Remove(opts.Output)
+		return fmt.Errorf("Backup failed: %v", err)
+	}
+
+	size, _ := pathSize(opts.Output)
+	printf("%s Backup written to %s (%s)\n", success("✔"), opts.Output, throughput(size, time.Since(start)))
+	recordBackup(containerName)
+
+	if opts.Checksum {
+		sum := hex.EncodeToString(hash.Sum(nil))
+		line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(opts.Output))
+		if err := os.WriteFile(opts.Output+".sha256", []byte(line), 0644); err != nil {
+			return fmt.Errorf("Failed to write checksum file: %v", err)
+		}
+		printf("%s Checksum written to %s.sha256 (sha256:%s)\n", success("✔"), opts.Output, sum)
+	}
+	if opts.Globals {
+		return backupGlobals(containerName, cfg, opts.Output)
+	}
+	return nil
+}
+
+// compressLevel returns the gzip level of a compressed backup. With --fast,
+// databases above largeDatabaseBytes are compressed at level 1, where gzip
+// rather than pg_dump would otherwise bound the backup's speed.
+func compressLevel(cfg *Config, opts BackupOptions) int {
+	if !opts.Fast || opts.Level == gzip.BestSpeed {
+		return opts.Level
+	}
+	out, err := psqlQuery(cfg, "SELECT pg_database_size(current_database())")
+	if err != nil {
+		printf("%s Warning: Could not determine database size, keeping level %d: %v\n", warn("⚠"), opts.Level, err)
+		return opts.Level
+	}
+	if size, err := strconv.ParseInt(out, 10, 64); err == nil && size > largeDatabaseBytes {
+		return gzip.BestSpeed
+	}
+	return opts.Level
+}
+
+// globalsPath returns the file holding the cluster-wide objects of a dump:
+// the dump's name without its extension plus -globals.sql
+func globalsPath(dump string) string {
+	dump = strings.TrimSuffix(dump, string(filepath.Separator))
+	dump = strings.TrimSuffix(dump, ".gz")
+	if ext := filepath.Ext(dump); ext == ".sql" || ext == ".dump" {
+		dump = strings.TrimSuffix(dump, ext)
+	}
+	return dump + "-globals.sql"
+}
+
+// backupGlobals dumps the roles and tablespaces, which pg_dump leaves out,
+// next to the database dump
+func backupGlobals(containerName string, cfg *Config, dump string) error {
+	path := globalsPath(dump)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to create globals file: %v", err)
+	}
+	defer file.Close()
+
+	if err := dockerClient.Stream(nil, file, os.Stderr, "exec", containerName, "pg_dumpall", "-U", cfg.Username, "-l", cfg.Database, "--globals-only"); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("Globals backup failed: %v", err)
+	}
+	printf("%s Roles and tablespaces written to %s\n", success("✔"), path)
+	return nil
+}
+
+// restoreGlobals applies a globals file before the dump, so the roles that
+// own objects and receive grants exist when the dump refers to them. Roles
+// that already exist, such as the container's own user, are left as they are:
+// their CREATE ROLE and ALTER ROLE lines are skipped, so neither their
+// password nor their attributes are overwritten.
+func restoreGlobals(containerName string, cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open globals file: %v", err)
+	}
+
+	roles, err := psqlQuery(cfg, "SELECT rolname FROM pg_roles")
+	if err != nil {
+		return fmt.Errorf("Failed to list existing roles: %v", err)
+	}
+	existing := make(map[string]bool)
+	for _, role := range strings.Split(roles, "\n") {
+		existing[role] = true
+	}
+
+	printf("%s Applying roles and tablespaces from %s...\n", info("ℹ"), path)
+	script, skipped := skipExisting(string(data), map[string]map[string]bool{
+		"CREATE ROLE ": existing,
+		"ALTER ROLE ":  existing,
+	})
+	if len(skipped) > 0 {
+		printf("%s Keeping existing roles as they are: %s\n", info("ℹ"), strings.Join(skipped, ", "))
+	}
+
+	var output bytes.Buffer
+	err = dockerClient.Stream(strings.NewReader(script), &output, &output, "exec", "-i", containerName, "psql", "-U", cfg.Username, "-d", cfg.Database, "-q")
+	if err != nil {
+		return fmt.Errorf("Failed to apply globals: %v: %s", err, strings.TrimSpace(output.String()))
+	}
+
+	// psql keeps going after an error; only objects that already exist, such
+	// as tablespaces, are expected to fail
+	var failures []string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.Contains(line, "ERROR:") && !strings.Contains(line, "already exists") {
+			failures = append(failures, strings.TrimSpace(line))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("Failed to apply globals from %s:\n  %s", path, strings.Join(failures, "\n  "))
+	}
+	return nil
+}
+
+// skipExisting drops the statements of a pg_dumpall script that would
+// create or alter objects which already exist: a line starting with one of
+// the prefixes, such as "CREATE ROLE ", is left out when the name that
+// follows is in that prefix's set. It returns the script along with the
+// skipped names.
+func skipExisting(script string, existing map[string]map[string]bool) (string, []string) {
+	var kept []string
+	var skipped []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(script, "\n") {
+		for prefix, names := range existing {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			if name := leadingName(strings.TrimPrefix(line, prefix)); names[name] {
+				if !seen[name] {
+					seen[name] = true
+					skipped = append(skipped, name)
+				}
+				line = ""
+			}
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), skipped
+}
+
+// leadingName reads the object name at the start of a statement's
+// remainder, unquoting it if needed
+func leadingName(rest string) string {
+	if strings.HasPrefix(rest, `"`) {
+		var name strings.Builder
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '"' {
+				if i+1 < len(rest) && rest[i+1] == '"' {
+					name.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			name.WriteByte(rest[i])
+		}
+		return name.String()
+	}
+	if end := strings.IndexAny(rest, " ;"); end >= 0 {
+		return rest[:end]
+	}
+	return rest
+}
+
+// pgDumpArgs builds the pg_dump command line shared by all backup formats
+func pgDumpArgs(cfg *Config, opts BackupOptions) []string {
+	args := []string{"pg_dump", "-U", cfg.Username, "-d", cfg.Database, "--format", opts.Format}
+	if opts.SchemaOnly {
+		args = append(args, "--schema-only")
+	}
+	if opts.DataOnly {
+		args = append(args, "--data-only")
+	}
+	for _, schema := range opts.Schemas {
+		args = append(args, "-n", schema)
+	}
+	return args
+}
+
+// backupDirectory runs a directory-format pg_dump inside the container and copies the result out
+func backupDirectory(containerName string, cfg *Config, opts BackupOptions) error {
+	tmpDir := fmt.Sprintf("/tmp/go-db-backup-%d", time.Now().UnixNano())
+	defer dockerClient.Run("exec", containerName, "rm", "-rf", tmpDir)
+
+	args := append([]string{"exec", containerName}, pgDumpArgs(cfg, opts)...)
+	args = append(args, "-f", tmpDir)
+	if opts.Jobs > 1 {
+		args = append(args, "-j", strconv.Itoa(opts.Jobs))
+	}
+	if err := dockerClient.Stream(nil, nil, os.Stderr, args...); err != nil {
+		return fmt.Errorf("Backup failed: %v", err)
+	}
+
+	if output, err := dockerClient.CombinedOutput("cp", containerName+":"+tmpDir, opts.Output); err != nil {
+		return fmt.Errorf("Failed to copy backup out of the container: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Restore loads a dump file into a database, using pg_restore for custom-format
+// archives and psql for plain SQL
+func Restore(containerName string, opts RestoreOptions) error {
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	if opts.Checksum != "" {
+		if err := verifyChecksum(opts.Input, opts.Checksum); err != nil {
+			return err
+		}
+		printf("%s Checksum verified\n", success("✔"))
+	}
+
+	if opts.Jobs < 0 {
+		return fmt.Errorf("--jobs cannot be negative")
+	}
+
+	stat, err := os.Stat(opts.Input)
+	if err != nil {
+		return fmt.Errorf("Failed to open dump file: %v", err)
+	}
+	if err := validateSchemas(opts.Schemas); err != nil {
+		return err
+	}
+	if len(opts.Schemas) > 0 && !stat.IsDir() && !isCustomArchive(opts.Input) {
+		return fmt.Errorf("--schema requires a custom or directory format dump; for plain SQL, back up with --schema instead")
+	}
+
+	// Roles must exist before the dump's ownership and privilege statements run
+	globals := globalsPath(opts.Input)
+	if _, err := os.Stat(globals); err == nil && !opts.NoGlobals {
+		if err := restoreGlobals(containerName, cfg, globals); err != nil {
+			return err
+		}
+	}
+
+	printf("%s Restoring %s into %s...\n", info("ℹ"), opts.Input, containerName)
+	start := time.Now()
+
+	if stat.IsDir() || opts.Jobs > 1 {
+		// Directory archives and parallel restores need pg_restore to read from a path
+		if !stat.IsDir() && !isCustomArchive(opts.Input) {
+			return fmt.Errorf("parallel restores (--jobs) require a custom or directory format dump")
+		}
+		if err := restoreFromPath(containerName, cfg, opts); err != nil {
+			return err
+		}
+	} else {
+		file, err := os.Open(opts.Input)
+		if err != nil {
+			return fmt.Errorf("Failed to open dump file: %v", err)
+		}
+		defer file.Close()
+
+		reader := bufio.NewReader(file)
+		if header, _ := reader.Peek(2); len(header) == 2 && header[0] == 0x1f && header[1] == 0x8b {
+			// Compressed backup
+			gz, err := gzip.NewReader(reader)
+			if err != nil {
+				return fmt.Errorf("Failed to read compressed dump: %v", err)
+			}
+			defer gz.Close()
+			reader = bufio.NewReader(gz)
+		}
+		var input io.Reader = reader
+		args := []string{"exec", "-i", containerName}
+		if header, _ := reader.Peek(5); string(header) == "PGDMP" {
+			args = append(args, pgRestoreArgs(cfg, opts)...)
+		} else {
+			args = append(args, "psql", "-U", cfg.Username, "-d", cfg.Database, "-v", "ON_ERROR_STOP=1", "-q")
+			if opts.NoOwner || opts.NoACL {
+				input = filterPlainDump(reader, opts.NoOwner, opts.NoACL)
+			}
+		}
+
+		if err := dockerClient.Stream(input, io.Discard, os.Stderr, args...); err != nil {
+			return fmt.Errorf("Restore failed: %v", err)
+		}
+	}
+
+	size, _ := pathSize(opts.Input)
+	printf("%s Restore completed successfully (%s)\n", success("✔"), throughput(size, time.Since(start)))
+
+	if opts.ReportSize {
+		dbSize, err := psqlQuery(cfg, "SELECT pg_size_pretty(pg_database_size(current_database()))")
+		if err != nil {
+			printf("%s Warning: Could not determine database size: %v\n", warn("⚠"), err)
+		} else {
+			printf("%s Database %s is now %s\n", info("ℹ"), cfg.Database, dbSize)
+		}
+	}
+	return nil
+}
+
+// restoreFromPath copies a dump into the container and runs pg_restore against it
+func restoreFromPath(containerName string, cfg *Config, opts RestoreOptions) error {
+	tmpPath := fmt.Sprintf("/tmp/go-db-restore-%d", time.Now().UnixNano())
+	defer dockerClient.Run("exec", containerName, "rm", "-rf", tmpPath)
+
+	if output, err := dockerClient.CombinedOutput("cp", opts.Input, containerName+":"+tmpPath); err != nil {
+		return fmt.Errorf("Failed to copy dump into the container: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	args := append([]string{"exec", containerName}, pgRestoreArgs(cfg, opts)...)
+	if opts.Jobs > 1 {
+		args = append(args, "-j", strconv.Itoa(opts.Jobs))
+	}
+	if err := dockerClient.Stream(nil, nil, os.Stderr, append(args, tmpPath)...); err != nil {
+		return fmt.Errorf("Restore failed: %v", err)
+	}
+	return nil
+}
+
+// pgRestoreArgs builds the pg_restore command line shared by all archive restores
+func pgRestoreArgs(cfg *Config, opts RestoreOptions) []string {
+	args := []string{"pg_restore", "-U", cfg.Username, "-d", cfg.Database}
+	if opts.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if opts.NoACL {
+		args = append(args, "--no-acl")
+	}
+	for _, schema := range opts.Schemas {
+		args = append(args, "-n", schema)
+	}
+	return args
+}
+
+// validateSchemas rejects empty --schema values, which pg_dump and pg_restore
+// would otherwise report with a less helpful message
+func validateSchemas(schemas []string) error {
+	for _, schema := range schemas {
+		if strings.TrimSpace(schema) == "" {
+			return fmt.Errorf("--schema must not be empty")
+		}
+	}
+	return nil
+}
+
+// filterPlainDump strips ownership and/or privilege statements from a plain SQL
+// dump, the psql equivalent of pg_restore's --no-owner and --no-acl. Lines inside
+// COPY data blocks are passed through untouched.
+func filterPlainDump(r *bufio.Reader, noOwner, noACL bool) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		inCopy := false
+		for {
+			line, err := r.ReadString('\n')
+			if len(line) > 0 {
+				trimmed := strings.TrimSpace(line)
+				skip := false
+				switch {
+				case inCopy:
+					inCopy = trimmed != `\.`
+				case strings.HasPrefix(trimmed, "COPY ") && strings.HasSuffix(trimmed, "FROM stdin;"):
+					inCopy = true
+				case noOwner && strings.HasPrefix(trimmed, "ALTER ") && strings.Contains(trimmed, " OWNER TO "):
+					skip = true
+				case noACL && (strings.HasPrefix(trimmed, "GRANT ") || strings.HasPrefix(trimmed, "REVOKE ") ||
+					strings.HasPrefix(trimmed, "ALTER DEFAULT PRIVILEGES ")):
+					skip = true
+				}
+				if !skip {
+					if _, werr := io.WriteString(pw, line); werr != nil {
+						return
+					}
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+// isCustomArchive reports whether a file is a pg_dump custom-format archive
+func isCustomArchive(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 5)
+	n, _ := io.ReadFull(file, header)
+	return string(header[:n]) == "PGDMP"
+}
+
+// pathSize returns the size of a file, or the total size of the files in a directory
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// throughput formats the amount of data moved and its rate
+func throughput(bytes int64, elapsed time.Duration) string {
+	mb := float64(bytes) / (1024 * 1024)
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return fmt.Sprintf("%.1f MB", mb)
+	}
+	return fmt.Sprintf("%.1f MB in %s, %.1f MB/s", mb, elapsed.Round(time.Millisecond), mb/seconds)
+}
+
+// verifyChecksum compares a file's SHA-256 hash with an expected sha256:<hex> value
+func verifyChecksum(path, expected string) error {
+	algo, want, ok := strings.Cut(expected, ":")
+	if !ok || algo != "sha256" || want == "" {
+		return fmt.Errorf("invalid checksum %q, expected format sha256:<hex>", expected)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dump file: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read dump file: %v", err)
+	}
+
+	got := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, want, got)
+	}
+	return nil
+}
+
+// runningConfig returns the configuration of an existing, running container
+func runningConfig(containerName string) (*Config, error) {
+	if exists, running := containerExists(containerName); !exists {
+		return nil, dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	} else if !running {
+		return nil, dberrors.Newf(dberrors.ErrContainerNotRunning, "Container %s is not running", containerName)
+	}
+
+	cfg, err := inspectConfig(containerName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get container details: %v", err)
+	}
+	return cfg, nil
+}
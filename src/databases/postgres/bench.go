@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BenchOptions controls a pgbench run
+type BenchOptions struct {
+	Clients int // concurrent client sessions
+	Jobs    int // pgbench worker threads (default: one per client, at most 4)
+	Time    int // benchmark duration in seconds
+	Scale   int // pgbench scale factor used for initialization
+}
+
+var (
+	tpsPattern     = regexp.MustCompile(`tps = ([\d.]+) \(without initial connection time\)`)
+	latencyPattern = regexp.MustCompile(`latency average = ([\d.]+ ms)`)
+	txPattern      = regexp.MustCompile(`number of transactions actually processed: (\d+)`)
+)
+
+// Bench initializes the pgbench tables in a running container's database and
+// runs pgbench against it, printing the resulting throughput
+func Bench(containerName string, opts BenchOptions) error {
+	if opts.Clients < 1 || opts.Time < 1 || opts.Scale < 1 {
+		return fmt.Errorf("--clients, --time and --scale must be at least 1")
+	}
+	if opts.Jobs <= 0 {
+		opts.Jobs = opts.Clients
+		if opts.Jobs > 4 {
+			opts.Jobs = 4
+		}
+	}
+
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	if err := dockerClient.Run("exec", containerName, "which", "pgbench"); err != nil {
+		return fmt.Errorf("pgbench is not available in container %s", containerName)
+	}
+
+	printf("%s Initializing pgbench tables (scale %d) in %s...\n", info("ℹ"), opts.Scale, cfg.Database)
+	if output, err := pgbench(cfg, "-i", "-q", "-s", strconv.Itoa(opts.Scale)); err != nil {
+		return fmt.Errorf("pgbench initialization failed: %v: %s", err, strings.TrimSpace(output))
+	}
+
+	printf("%s Running pgbench with %d clients for %ds...\n", info("ℹ"), opts.Clients, opts.Time)
+	output, err := pgbench(cfg,
+		"-c", strconv.Itoa(opts.Clients),
+		"-j", strconv.Itoa(opts.Jobs),
+		"-T", strconv.Itoa(opts.Time))
+	if err != nil {
+		return fmt.Errorf("pgbench failed: %v: %s", err, strings.TrimSpace(output))
+	}
+
+	tps := tpsPattern.FindStringSubmatch(output)
+	if tps == nil {
+		return fmt.Errorf("Could not find the TPS in the pgbench output:\n%s", output)
+	}
+
+	printf("\n%s Benchmark Results:\n", info("ℹ"))
+	printf("  %s TPS: %s\n", info("→"), tps[1])
+	if m := latencyPattern.FindStringSubmatch(output); m != nil {
+		printf("  %s Latency (avg): %s\n", info("→"), m[1])
+	}
+	if m := txPattern.FindStringSubmatch(output); m != nil {
+		printf("  %s Transactions: %s\n", info("→"), m[1])
+	}
+	printf("  %s Clients: %d, threads: %d, scale: %d\n", info("→"), opts.Clients, opts.Jobs, opts.Scale)
+	return nil
+}
+
+// pgbench runs pgbench inside the container against its database
+func pgbench(cfg *Config, args ...string) (string, error) {
+	cmdArgs := append([]string{"exec", cfg.ContainerName, "pgbench", "-U", cfg.Username}, args...)
+	cmdArgs = append(cmdArgs, cfg.Database)
+	output, err := dockerClient.CombinedOutput(cmdArgs...)
+	return string(output), err
+}
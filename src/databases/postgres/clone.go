@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awade12/go-db/src/databases"
+)
+
+// Clone creates a new container with the version and settings of a running
+// source container and loads a pg_dump of the source's database into it. The
+// dump is piped from one container to the other and never touches the disk.
+// The clone gets its own password, shown in the connection details create
+// prints.
+func Clone(sourceName, targetName string) error {
+	if err := databases.ValidateContainerName(targetName); err != nil {
+		return err
+	}
+	source, err := runningConfig(sourceName)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cloneConfig(source, targetName)
+	if err != nil {
+		return err
+	}
+	if err := CreateWithConfig(cfg); err != nil {
+		return err
+	}
+
+	printf("%s Copying database %s from %s...\n", info("ℹ"), source.Database, sourceName)
+	start := time.Now()
+	if err := streamDump(source, cfg); err != nil {
+		return fmt.Errorf("Failed to copy %s into %s: %v; the new container was kept, remove it with go-db remove %s",
+			sourceName, cfg.ContainerName, err, cfg.ContainerName)
+	}
+	printf("%s Cloned %s into %s in %s; it uses the connection details above\n",
+		success("✔"), sourceName, cfg.ContainerName, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// cloneConfig builds the configuration of a clone from its source: same
+// image, version, user, database, locale, timezone and resource limits, but
+// a fresh password and its own port
+func cloneConfig(source *Config, targetName string) (*Config, error) {
+	cfg := DefaultConfig(targetName)
+	cfg.Version = source.Version
+	cfg.Username = source.Username
+	cfg.Database = source.Database
+	if source.Timezone != "" {
+		cfg.Timezone = source.Timezone
+	}
+	if source.Locale != "" {
+		cfg.Locale = source.Locale
+	}
+
+	// The source's image is already there, so it isn't pulled again
+	image, err := containerImage(source.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	cfg.Image = image
+	cfg.SkipPull = true
+
+	cfg.Memory, cfg.CPU, err = containerLimits(source.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// containerLimits returns a container's memory and CPU limits in the form
+// --memory and --cpu take, empty when unlimited
+func containerLimits(containerName string) (memory, cpu string, err error) {
+	output, err := dockerClient.Inspect(containerName, "{{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to inspect limits of %s: %v", containerName, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return "", "", nil
+	}
+	if bytes, _ := strconv.ParseInt(fields[0], 10, 64); bytes > 0 {
+		memory = fmt.Sprintf("%db", bytes)
+	}
+	if nanos, _ := strconv.ParseInt(fields[1], 10, 64); nanos > 0 {
+		cpu = strconv.FormatFloat(float64(nanos)/1e9, 'f', -1, 64)
+	}
+	return memory, cpu, nil
+}
+
+// streamDump pipes pg_dump in the source container into psql in the target.
+// Ownership and privileges are left out, since the source's other roles don't
+// exist in the clone.
+func streamDump(source, target *Config) error {
+	var dumpErr, loadErr bytes.Buffer
+	pr, pw := io.Pipe()
+	dumped := make(chan error, 1)
+	go func() {
+		err := dockerClient.Stream(nil, pw, &dumpErr, "exec", source.ContainerName,
+			"pg_dump", "-U", source.Username, "-d", source.Database, "--no-owner", "--no-acl")
+		pw.CloseWithError(err)
+		dumped <- err
+	}()
+
+	loadFailed := dockerClient.Stream(pr, nil, &loadErr, "exec", "-i", target.ContainerName,
+		"psql", "-U", target.Username, "-d", target.Database, "-v", "ON_ERROR_STOP=1", "-q")
+	// Closing the read end makes pg_dump fail on its next write if psql quit early
+	pr.Close()
+	dumpFailed := <-dumped
+	if loadFailed != nil {
+		return fmt.Errorf("psql: %v: %s", loadFailed, strings.TrimSpace(loadErr.String()))
+	}
+	if dumpFailed != nil {
+		return fmt.Errorf("pg_dump: %v: %s", dumpFailed, strings.TrimSpace(dumpErr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// ConnectOptions controls interactive sessions inside a container
+type ConnectOptions struct {
+	DetachKeys string // key sequence for detaching from the session, passed to docker exec
+	Command    string // SQL to run with psql -c instead of opening a session
+}
+
+// Connect opens an interactive psql session in a running container using the
+// credentials it was created with, or runs a single SQL command with them
+func Connect(containerName string, opts ConnectOptions) error {
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+	command := []string{"psql", "-U", cfg.Username, "-d", cfg.Database}
+	if opts.Command != "" {
+		command = append(command, "-c", opts.Command)
+	}
+	return interactiveExec(containerName, command, opts)
+}
+
+// Exec runs a command inside a running container with the caller's terminal attached
+func Exec(containerName string, command []string, opts ConnectOptions) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command given")
+	}
+	if _, err := runningConfig(containerName); err != nil {
+		return err
+	}
+	return interactiveExec(containerName, command, opts)
+}
+
+// interactiveExec runs docker exec attached to our own stdin, stdout and
+// stderr. When both ends are terminals it allocates a TTY, so the docker
+// client puts the terminal in raw mode and forwards window size changes
+// (SIGWINCH), which pagers and editors inside psql depend on.
+func interactiveExec(containerName string, command []string, opts ConnectOptions) error {
+	args := []string{"exec", "-i"}
+	if utils.IsInteractive() {
+		args = append(args, "-t")
+		if t := os.Getenv("TERM"); t != "" {
+			args = append(args, "-e", "TERM="+t)
+		}
+	}
+	if opts.DetachKeys != "" {
+		args = append(args, "--detach-keys", opts.DetachKeys)
+	}
+	args = append(args, containerName)
+	args = append(args, command...)
+
+	if err := dockerClient.Stream(os.Stdin, os.Stdout, os.Stderr, args...); err != nil {
+		return fmt.Errorf("session ended with an error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+)
+
+const dataDir = "/var/lib/postgresql/data"
+
+// isNamedVolume reports whether a volume reference names a docker volume rather than a host path
+func isNamedVolume(volume string) bool {
+	return volume != "" && !strings.HasPrefix(volume, "/") && !strings.HasPrefix(volume, ".") && !strings.HasPrefix(volume, "~")
+}
+
+// containerDataMount returns the volume name or host path mounted at the container's data directory
+func containerDataMount(containerName string) (string, error) {
+	format := fmt.Sprintf(`{{range .Mounts}}{{if eq .Destination %q}}{{if .Name}}{{.Name}}{{else}}{{.Source}}{{end}}{{end}}{{end}}`, dataDir)
+	output, err := dockerClient.Inspect(containerName, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect mounts of %s: %v", containerName, err)
+	}
+
+	mount := strings.TrimSpace(string(output))
+	if mount == "" {
+		return "", fmt.Errorf("container %s has no data volume mounted at %s", containerName, dataDir)
+	}
+	return mount, nil
+}
+
+// prepareCopy validates the copy source and aligns the new container's configuration with it.
+// The copied data directory is already initialized, so the source's credentials stay in effect.
+func prepareCopy(cfg *Config) error {
+	exists, running := containerExists(cfg.CopyFrom)
+	if !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Source container %s does not exist", cfg.CopyFrom)
+	}
+	if running {
+		printf("%s Warning: Source container %s is running; stop it first for a consistent copy\n", warn("⚠"), cfg.CopyFrom)
+	}
+
+	source, err := inspectConfig(cfg.CopyFrom)
+	if err != nil {
+		return fmt.Errorf("Failed to inspect source container: %v", err)
+	}
+	if source.Version != "" && source.Version != cfg.Version {
+		printf("%s Warning: Source runs version %s but %s was requested; the data directory may be incompatible\n",
+			warn("⚠"), source.Version, cfg.Version)
+	}
+
+	cfg.Username = source.Username
+	cfg.Password = source.Password
+	cfg.Database = source.Database
+	printf("%s Credentials are inherited from %s\n", info("ℹ"), cfg.CopyFrom)
+
+	if cfg.Volume == "" {
+		cfg.Volume = cfg.ContainerName + "-data"
+	}
+	if isNamedVolume(cfg.Volume) {
+		if err := dockerClient.Run("volume", "inspect", cfg.Volume); err == nil {
+			return fmt.Errorf("Volume %s already exists; choose another --volume for the copy", cfg.Volume)
+		}
+	}
+	return nil
+}
+
+// copyDataVolume copies the source container's data directory into the target volume
+// using a temporary alpine container
+func copyDataVolume(sourceContainer, targetVolume, driver string) error {
+	source, err := containerDataMount(sourceContainer)
+	if err != nil {
+		return err
+	}
+
+	if isNamedVolume(targetVolume) {
+		if err := createManagedVolume(targetVolume, driver); err != nil {
+			return err
+		}
+	}
+
+	output, err := dockerClient.CombinedOutput("run", "--rm",
+		"-v", fmt.Sprintf("%s:/from:ro", source),
+		"-v", fmt.Sprintf("%s:/to", targetVolume),
+		"alpine", "sh", "-c", "cp -a /from/. /to/")
+	if err != nil {
+		return fmt.Errorf("copy failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
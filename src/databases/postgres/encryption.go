@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// dataHostPath returns the host directory the data directory will be stored
+// in: the bind-mounted path, the named volume's mountpoint, or the runtime's
+// volume directory for volumes that don't exist yet
+func dataHostPath(cfg *Config) (string, error) {
+	if cfg.Volume != "" && !isNamedVolume(cfg.Volume) {
+		path, err := utils.ExpandHome(cfg.Volume)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(path)
+	}
+
+	if cfg.Volume != "" {
+		if output, err := dockerClient.Output("volume", "inspect", "--format", "{{.Mountpoint}}", cfg.Volume); err == nil {
+			return strings.TrimSpace(string(output)), nil
+		}
+	}
+	output, err := dockerClient.Output("info", "--format", "{{.DockerRootDir}}")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the docker data directory: %v", err)
+	}
+	return filepath.Join(strings.TrimSpace(string(output)), "volumes"), nil
+}
+
+// checkEncryptedVolume refuses to create a database whose data would be
+// written to unencrypted storage. Where encryption can't be detected, it warns
+// and carries on.
+func checkEncryptedVolume(cfg *Config) error {
+	if cfg.Ephemeral {
+		printf("%s Data is kept on tmpfs and never written to disk\n", info("ℹ"))
+		return nil
+	}
+
+	path, err := dataHostPath(cfg)
+	if err != nil {
+		return err
+	}
+	encrypted, device, err := utils.EncryptedStorage(path)
+	if errors.Is(err, utils.ErrEncryptionUnsupported) {
+		printf("%s %s\n", warn("⚠"), errColor(fmt.Sprintf("Warning: --require-encrypted-volume could not be checked: %v; make sure %s is encrypted", err, path)))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to check the encryption of %s: %v", path, err)
+	}
+	if !encrypted {
+		return fmt.Errorf("%s is on %s, which is not encrypted; --require-encrypted-volume needs a LUKS/dm-crypt backed --volume", path, device)
+	}
+	printf("%s %s is on encrypted device %s\n", success("✔"), path, device)
+	return nil
+}
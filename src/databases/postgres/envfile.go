@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// envFileKeys are the variables WriteEnvFile writes, in order
+var envFileKeys = []string{"PGHOST", "PGPORT", "PGUSER", "PGPASSWORD", "PGDATABASE", "DATABASE_URL"}
+
+// envAssignmentPattern matches a variable assignment line of a .env file
+var envAssignmentPattern = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=`)
+
+// checkEnvFile fails early, before anything is created, when --env-file
+// already defines one of the variables and --force was not given
+func checkEnvFile(cfg *Config) error {
+	if cfg.EnvFile == "" || cfg.Force {
+		return nil
+	}
+	data, err := os.ReadFile(cfg.EnvFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read --env-file: %v", err)
+	}
+	if defined := definedEnvKeys(string(data)); len(defined) > 0 {
+		return fmt.Errorf("%s already defines %s; pass --force to replace them", cfg.EnvFile, strings.Join(defined, ", "))
+	}
+	return nil
+}
+
+// WriteEnvFile writes the connection details as PG* variables and a
+// DATABASE_URL to a .env file. An existing file gets them appended under a
+// comment naming the container; with --force, earlier definitions of the same
+// variables are removed first.
+func WriteEnvFile(cfg *Config, path string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := string(existing)
+	if defined := definedEnvKeys(content); len(defined) > 0 {
+		if !cfg.Force {
+			return fmt.Errorf("%s already defines %s; pass --force to replace them", path, strings.Join(defined, ", "))
+		}
+		content = removeEnvKeys(content)
+	}
+
+	details := ConnectionDetails(cfg)
+	databaseURL := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(cfg.Username, cfg.Password),
+		Host:   net.JoinHostPort(details.Host, cfg.Port),
+		Path:   "/" + cfg.Database,
+	}
+	values := map[string]string{
+		"PGHOST":       details.Host,
+		"PGPORT":       cfg.Port,
+		"PGUSER":       cfg.Username,
+		"PGPASSWORD":   cfg.Password,
+		"PGDATABASE":   cfg.Database,
+		"DATABASE_URL": databaseURL.String(),
+	}
+
+	var b strings.Builder
+	b.WriteString(content)
+	if content != "" {
+		if !strings.HasSuffix(content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "# go-db: %s (created %s)\n", cfg.ContainerName, time.Now().Format(time.RFC3339))
+	for _, key := range envFileKeys {
+		fmt.Fprintf(&b, "%s=%s\n", key, envQuote(values[key]))
+	}
+
+	// The file holds a password, so keep it private
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// definedEnvKeys returns which of the variables WriteEnvFile writes are
+// already assigned in content
+func definedEnvKeys(content string) []string {
+	assigned := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		if m := envAssignmentPattern.FindStringSubmatch(line); m != nil {
+			assigned[m[1]] = true
+		}
+	}
+
+	var defined []string
+	for _, key := range envFileKeys {
+		if assigned[key] {
+			defined = append(defined, key)
+		}
+	}
+	return defined
+}
+
+// removeEnvKeys drops the assignments of the variables WriteEnvFile writes
+func removeEnvKeys(content string) string {
+	ours := make(map[string]bool)
+	for _, key := range envFileKeys {
+		ours[key] = true
+	}
+
+	var kept []string
+	for _, line := range strings.Split(content, "\n") {
+		if m := envAssignmentPattern.FindStringSubmatch(line); m != nil && ours[m[1]] {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// envQuote quotes a .env value when needed: single quotes keep $ and # literal,
+// double quotes with escapes are the fallback for values containing a single quote
+func envQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t#$'\"\\`=!&;|<>(){}[]*?") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + escaper.Replace(value) + `"`
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Event types emitted while an operation runs
+const (
+	EventStepStarted   = "step_started"
+	EventStepCompleted = "step_completed"
+	EventReady         = "container_ready"
+	EventError         = "error"
+)
+
+// Event is a single structured record of an operation's progress
+type Event struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Container string    `json:"container"`
+	Type      string    `json:"event"`
+	Step      string    `json:"step,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// emitEvent writes the event as a single JSON line to stderr when JSON logging is enabled
+func emitEvent(cfg *Config, operation, eventType, step, message string) {
+	if !cfg.JSONLogs {
+		return
+	}
+
+	line, err := json.Marshal(Event{
+		Time:      time.Now().UTC(),
+		Operation: operation,
+		Container: cfg.ContainerName,
+		Type:      eventType,
+		Step:      step,
+		Message:   message,
+	})
+	if err != nil {
+		return
+	}
+	os.Stderr.Write(append(line, '\n'))
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awade12/go-db/src/databases"
+	dberrors "github.com/awade12/go-db/src/errors"
+)
+
+// healthStatus returns docker's health status of a container (starting,
+// healthy or unhealthy), or "" when the container has no healthcheck
+func healthStatus(containerName string) (string, error) {
+	output, err := dockerClient.Inspect(containerName, "{{if .State.Health}}{{.State.Health.Status}}{{end}}")
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect health of %s: %v", containerName, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// healthDeadline is how long docker may take to decide on the container's
+// health: the start period plus every allowed attempt, with some slack
+func healthDeadline(cfg *Config) time.Duration {
+	duration := func(value, fallback string) time.Duration {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		d, _ := time.ParseDuration(fallback)
+		return d
+	}
+
+	retries := cfg.HealthRetries
+	if retries <= 0 {
+		retries = defaultHealthRetries
+	}
+	interval := duration(cfg.HealthInterval, defaultHealthInterval)
+	timeout := duration(cfg.HealthTimeout, defaultHealthTimeout)
+	startPeriod := duration(cfg.HealthStartPeriod, defaultHealthStartPeriod)
+	return startPeriod + time.Duration(retries+1)*(interval+timeout) + 10*time.Second
+}
+
+// waitForHealthy polls docker's health status until the container is healthy.
+// It reports false when the container has no healthcheck, so the caller can
+// fall back to probing it directly.
+func waitForHealthy(cfg *Config) (bool, error) {
+	deadline := time.Now().Add(healthDeadline(cfg))
+	for {
+		status, err := healthStatus(cfg.ContainerName)
+		if err != nil {
+			return true, err
+		}
+
+		switch status {
+		case "":
+			return false, nil
+		case "healthy":
+			return true, nil
+		case "unhealthy":
+			return true, fmt.Errorf("container %s is unhealthy; check go-db logs %s", cfg.ContainerName, cfg.ContainerName)
+		}
+
+		if time.Now().After(deadline) {
+			return true, fmt.Errorf("timeout waiting for container %s to become healthy (status: %s)", cfg.ContainerName, status)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// healthRetryInterval is the pause between checks while health waits for a
+// container to become healthy
+const healthRetryInterval = time.Second
+
+// Health checks once whether a container is healthy: running, not reported
+// unhealthy or starting by its docker healthcheck, and accepting connections
+// according to pg_isready. It prints the status; the error is for containers
+// that can't be checked at all.
+func Health(containerName string) (bool, error) {
+	return HealthWithTimeout(containerName, 0)
+}
+
+// HealthWithTimeout is Health, retrying until the container is healthy or the
+// timeout has passed
+func HealthWithTimeout(containerName string, timeout time.Duration) (bool, error) {
+	if exists, _ := containerExists(containerName); !exists {
+		return false, dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	}
+	cfg, err := inspectConfig(containerName)
+	if err != nil {
+		return false, fmt.Errorf("Failed to get container details: %v", err)
+	}
+
+	attempts := 1 + int(timeout/healthRetryInterval)
+	err = retry(attempts, healthRetryInterval, func() error {
+		return checkHealth(cfg)
+	})
+	if err != nil {
+		printf("%s %s is unhealthy: %v\n", errColor("✘"), containerName, err)
+		return false, nil
+	}
+	printf("%s %s is healthy\n", success("✔"), containerName)
+	return true, nil
+}
+
+// checkHealth returns why a container is not healthy, or nil
+func checkHealth(cfg *Config) error {
+	if _, running := containerExists(cfg.ContainerName); !running {
+		return fmt.Errorf("container is not running")
+	}
+
+	status, err := healthStatus(cfg.ContainerName)
+	if err != nil {
+		return err
+	}
+	if status == "unhealthy" || status == "starting" {
+		return fmt.Errorf("docker healthcheck status is %s", status)
+	}
+
+	probe := append(append([]string(nil), databases.Postgres.ReadyCommand...), "-U", cfg.Username, "-d", cfg.Database)
+	return probeReady(cfg.ContainerName, probe, probeTimeout(cfg))
+}
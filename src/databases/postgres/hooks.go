@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// runHook runs a local shell command with the container's details exposed as GODB_* environment variables
+func runHook(hook string, cfg *Config) error {
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GODB_NAME=%s", cfg.ContainerName),
+		fmt.Sprintf("GODB_PORT=%s", cfg.Port),
+		fmt.Sprintf("GODB_USER=%s", cfg.Username),
+		fmt.Sprintf("GODB_PASSWORD=%s", cfg.Password),
+		fmt.Sprintf("GODB_DATABASE=%s", cfg.Database),
+		fmt.Sprintf("GODB_VERSION=%s", cfg.Version),
+	)
+	cmd.Stdout = utils.Output
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,368 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+)
+
+// ContainerInfo is the part of docker inspect that matters for a database
+// container, in a stable shape that doesn't follow docker's API versions
+type ContainerInfo struct {
+	Name          string                 `json:"name"`
+	ID            string                 `json:"id"`
+	Image         string                 `json:"image"`
+	Created       time.Time              `json:"created"`
+	State         ContainerState         `json:"state"`
+	Env           map[string]string      `json:"env"` // passwords masked
+	Labels        map[string]string      `json:"labels,omitempty"`
+	Mounts        []MountInfo            `json:"mounts"`
+	Networks      map[string]NetworkInfo `json:"networks"`
+	Ports         []PortBinding          `json:"ports"`
+	Resources     Resources              `json:"resources"`
+	RestartPolicy string                 `json:"restartPolicy"`
+}
+
+// ContainerState is whether a container runs and since when
+type ContainerState struct {
+	Status       string     `json:"status"`
+	Running      bool       `json:"running"`
+	Health       string     `json:"health,omitempty"`
+	ExitCode     int        `json:"exitCode"`
+	RestartCount int        `json:"restartCount"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+}
+
+// MountInfo is a volume, bind mount or tmpfs of a container
+type MountInfo struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"` // named volumes only
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	Driver      string `json:"driver,omitempty"`
+	ReadOnly    bool   `json:"readOnly"`
+}
+
+// NetworkInfo is a container's address on one network
+type NetworkInfo struct {
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	IPv6Address string   `json:"ipv6Address,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// PortBinding maps a container port to a host address
+type PortBinding struct {
+	ContainerPort string `json:"containerPort"` // e.g. 5432/tcp
+	HostIP        string `json:"hostIp,omitempty"`
+	HostPort      string `json:"hostPort"`
+}
+
+// Resources are a container's limits; zero means unlimited
+type Resources struct {
+	MemoryBytes  int64   `json:"memoryBytes"`
+	CPUs         float64 `json:"cpus"`
+	ShmSizeBytes int64   `json:"shmSizeBytes"`
+}
+
+// dockerInspect is the subset of docker inspect's output ContainerInfo is built from
+type dockerInspect struct {
+	ID      string    `json:"Id"`
+	Name    string    `json:"Name"`
+	Created time.Time `json:"Created"`
+	State   struct {
+		Status     string    `json:"Status"`
+		Running    bool      `json:"Running"`
+		ExitCode   int       `json:"ExitCode"`
+		StartedAt  time.Time `json:"StartedAt"`
+		FinishedAt time.Time `json:"FinishedAt"`
+		Health     *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	RestartCount int    `json:"RestartCount"`
+	ImageID      string `json:"Image"`
+	Config       struct {
+		Image       string            `json:"Image"`
+		Env         []string          `json:"Env"`
+		Labels      map[string]string `json:"Labels"`
+		Cmd         []string          `json:"Cmd"`
+		StopSignal  string            `json:"StopSignal"`
+		Healthcheck *struct {
+			Interval    time.Duration `json:"Interval"`
+			Timeout     time.Duration `json:"Timeout"`
+			StartPeriod time.Duration `json:"StartPeriod"`
+			Retries     int           `json:"Retries"`
+		} `json:"Healthcheck"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory        int64             `json:"Memory"`
+		MemorySwap    int64             `json:"MemorySwap"`
+		NanoCpus      int64             `json:"NanoCpus"`
+		ShmSize       int64             `json:"ShmSize"`
+		AutoRemove    bool              `json:"AutoRemove"`
+		Tmpfs         map[string]string `json:"Tmpfs"`
+		RestartPolicy struct {
+			Name              string `json:"Name"`
+			MaximumRetryCount int    `json:"MaximumRetryCount"`
+		} `json:"RestartPolicy"`
+		PortBindings map[string][]dockerPortBinding `json:"PortBindings"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		Driver      string `json:"Driver"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Ports    map[string][]dockerPortBinding `json:"Ports"`
+		Networks map[string]struct {
+			IPAddress         string   `json:"IPAddress"`
+			GlobalIPv6Address string   `json:"GlobalIPv6Address"`
+			Aliases           []string `json:"Aliases"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+type dockerPortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+// inspectContainer runs docker inspect on a container and parses its output
+func inspectContainer(containerName string) (*dockerInspect, error) {
+	output, err := dockerClient.Output("inspect", "--type", "container", containerName)
+	if err != nil {
+		if exists, _ := containerExists(containerName); !exists {
+			return nil, dberrors.Wrapf(dberrors.ErrContainerNotFound, err, "Container %s does not exist", containerName)
+		}
+		return nil, fmt.Errorf("failed to inspect container %s: %v", containerName, err)
+	}
+	var inspected []dockerInspect
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse docker inspect output: %v", err)
+	}
+	return &inspected[0], nil
+}
+
+// Inspect returns the metadata of a container, with every environment
+// variable whose name mentions a password masked
+func Inspect(containerName string) (*ContainerInfo, error) {
+	d, err := inspectContainer(containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	ci := &ContainerInfo{
+		Name:     strings.TrimPrefix(d.Name, "/"),
+		ID:       d.ID,
+		Image:    d.Config.Image,
+		Created:  d.Created,
+		Env:      map[string]string{},
+		Labels:   d.Config.Labels,
+		Mounts:   []MountInfo{},
+		Networks: map[string]NetworkInfo{},
+		Ports:    []PortBinding{},
+		Resources: Resources{
+			MemoryBytes:  d.HostConfig.Memory,
+			CPUs:         float64(d.HostConfig.NanoCpus) / 1e9,
+			ShmSizeBytes: d.HostConfig.ShmSize,
+		},
+		RestartPolicy: d.HostConfig.RestartPolicy.Name,
+	}
+
+	ci.State = ContainerState{
+		Status:       d.State.Status,
+		Running:      d.State.Running,
+		ExitCode:     d.State.ExitCode,
+		RestartCount: d.RestartCount,
+	}
+	// docker reports times that never happened as year 1
+	if !d.State.StartedAt.IsZero() {
+		ci.State.StartedAt = &d.State.StartedAt
+	}
+	if !d.State.FinishedAt.IsZero() {
+		ci.State.FinishedAt = &d.State.FinishedAt
+	}
+	if d.State.Health != nil {
+		ci.State.Health = d.State.Health.Status
+	}
+
+	for _, entry := range d.Config.Env {
+		key, value, _ := strings.Cut(entry, "=")
+		if strings.Contains(strings.ToUpper(key), "PASSWORD") && value != "" {
+			value = redactedPassword
+		}
+		ci.Env[key] = value
+	}
+
+	if ci.RestartPolicy == "" {
+		ci.RestartPolicy = "no"
+	}
+	if ci.RestartPolicy == "on-failure" && d.HostConfig.RestartPolicy.MaximumRetryCount > 0 {
+		ci.RestartPolicy = fmt.Sprintf("on-failure:%d", d.HostConfig.RestartPolicy.MaximumRetryCount)
+	}
+
+	for _, m := range d.Mounts {
+		ci.Mounts = append(ci.Mounts, MountInfo{
+			Type:        m.Type,
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			Driver:      m.Driver,
+			ReadOnly:    !m.RW,
+		})
+	}
+
+	for name, n := range d.NetworkSettings.Networks {
+		ci.Networks[name] = NetworkInfo{IPAddress: n.IPAddress, IPv6Address: n.GlobalIPv6Address, Aliases: n.Aliases}
+	}
+
+	// A stopped container has no live port mappings, only the configured ones
+	bindings := d.NetworkSettings.Ports
+	if !d.State.Running {
+		bindings = d.HostConfig.PortBindings
+	}
+	for containerPort, hosts := range bindings {
+		for _, host := range hosts {
+			ci.Ports = append(ci.Ports, PortBinding{ContainerPort: containerPort, HostIP: host.HostIP, HostPort: host.HostPort})
+		}
+	}
+	sort.Slice(ci.Ports, func(i, j int) bool {
+		if ci.Ports[i].ContainerPort != ci.Ports[j].ContainerPort {
+			return ci.Ports[i].ContainerPort < ci.Ports[j].ContainerPort
+		}
+		return ci.Ports[i].HostIP < ci.Ports[j].HostIP
+	})
+	return ci, nil
+}
+
+// PrintInspect writes a container's metadata as indented JSON. With raw,
+// docker inspect's own output is passed through unchanged, passwords included.
+func PrintInspect(w io.Writer, containerName string, raw bool) error {
+	if raw {
+		if err := dockerClient.Stream(nil, w, os.Stderr, "inspect", "--type", "container", containerName); err != nil {
+			return fmt.Errorf("docker inspect failed: %v", err)
+		}
+		return nil
+	}
+
+	ci, err := Inspect(containerName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(ci)
+}
+
+// Tables lists the user tables of a running container's database with their sizes
+func Tables(containerName string) error {
+	return printQuery(containerName, "Tables",
+		[]string{"TABLE", "SIZE", "ROWS (EST.)"},
+		`SELECT schemaname || '.' || relname,
+			pg_size_pretty(pg_total_relation_size(relid)),
+			n_live_tup
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC, 1`)
+}
+
+// Databases lists the databases of a running container
+func Databases(containerName string) error {
+	return printQuery(containerName, "Databases",
+		[]string{"DATABASE", "OWNER", "ENCODING", "SIZE"},
+		`SELECT datname,
+			pg_get_userbyid(datdba),
+			pg_encoding_to_char(encoding),
+			pg_size_pretty(pg_database_size(datname))
+		FROM pg_database
+		WHERE NOT datistemplate
+		ORDER BY datname`)
+}
+
+// Users lists the roles of a running container, leaving out the built-in pg_* roles
+func Users(containerName string) error {
+	return printQuery(containerName, "Roles",
+		[]string{"ROLE", "ATTRIBUTES"},
+		`SELECT rolname,
+			concat_ws(', ',
+				CASE WHEN rolsuper THEN 'Superuser' END,
+				CASE WHEN rolcreaterole THEN 'Create role' END,
+				CASE WHEN rolcreatedb THEN 'Create DB' END,
+				CASE WHEN NOT rolcanlogin THEN 'Cannot login' END,
+				CASE WHEN rolreplication THEN 'Replication' END)
+		FROM pg_roles
+		WHERE rolname NOT LIKE 'pg\_%'
+		ORDER BY rolname`)
+}
+
+// printQuery runs a catalog query in a running container and prints the rows
+// as a table with the given column headers
+func printQuery(containerName, title string, headers []string, sql string) error {
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	rows, err := queryRows(cfg, sql)
+	if err != nil {
+		return fmt.Errorf("Failed to query %s: %v", strings.ToLower(title), err)
+	}
+
+	printf("\n%s %s in %s\n", info("📦"), title, containerName)
+	if len(rows) == 0 {
+		printf("\n  %s No %s found\n\n", warn("⚠"), strings.ToLower(title))
+		return nil
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	total := 0
+	for _, w := range widths {
+		total += w + 2
+	}
+
+	printf("\n  %s\n", formatRow(headers, widths))
+	printf("  %s\n", strings.Repeat("─", total))
+	for _, row := range rows {
+		printf("  %s\n", formatRow(row, widths))
+	}
+	printf("\n")
+	return nil
+}
+
+// formatRow pads each cell to its column width
+func formatRow(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, w := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		b.WriteString(cell)
+		if i < len(widths)-1 {
+			b.WriteString(strings.Repeat(" ", w-utf8.RuneCountInString(cell)+2))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/awade12/go-db/src/docker"
+	dberrors "github.com/awade12/go-db/src/errors"
+)
+
+func TestInspectContainerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]docker.FakeResponse
+		notFound  bool
+		wantMsg   string
+	}{
+		{
+			name: "missing container",
+			responses: map[string]docker.FakeResponse{
+				"inspect --type container shop": {Err: errFake},
+			},
+			notFound: true,
+			wantMsg:  "Container shop does not exist: " + errFake.Error(),
+		},
+		{
+			name: "docker failure on an existing container",
+			responses: map[string]docker.FakeResponse{
+				"ps -a --filter name=^shop$ ":   {Output: "Up 1 hour\n"},
+				"inspect --type container shop": {Err: errFake},
+			},
+			notFound: false,
+			wantMsg:  "failed to inspect container shop: " + errFake.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeClient(t, tt.responses)
+			_, err := inspectContainer("shop")
+			if err == nil {
+				t.Fatal("inspectContainer() succeeded, want an error")
+			}
+			if got := errors.Is(err, dberrors.ErrContainerNotFound); got != tt.notFound {
+				t.Errorf("errors.Is(err, ErrContainerNotFound) = %v, want %v (err: %v)", got, tt.notFound, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// reservedLabelPrefix is the namespace of the labels go-db sets itself
+const reservedLabelPrefix = "go-db."
+
+// validateLabels checks the --label keys; values may be anything, including empty
+func validateLabels(cfg *Config) error {
+	for key := range cfg.Labels {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid --label: the key is empty, expected key=value")
+		}
+		if strings.ContainsAny(key, " \t\n=") {
+			return fmt.Errorf("invalid --label %q: keys cannot contain whitespace or =", key)
+		}
+		if strings.HasPrefix(key, reservedLabelPrefix) {
+			return fmt.Errorf("invalid --label %q: the %s prefix is reserved for go-db's own labels", key, reservedLabelPrefix)
+		}
+	}
+	return nil
+}
+
+// labelArgs renders --label as docker run arguments, sorted by key
+func labelArgs(cfg *Config) []string {
+	var args []string
+	for _, label := range sortedParams(cfg.Labels) {
+		args = append(args, "--label", label)
+	}
+	return args
+}
+
+// userLabels returns the labels set on a container with --label: those not
+// inherited from its image and not in go-db's own namespace
+func userLabels(containerName string) (map[string]string, error) {
+	output, err := dockerClient.Inspect(containerName, "{{.Image}}\t{{json .Config.Labels}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container labels: %v", err)
+	}
+	imageID, labelJSON, _ := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(labelJSON), &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse container labels: %v", err)
+	}
+
+	// Labels baked into the image show up on the container too
+	var imageLabels map[string]string
+	if output, err := dockerClient.Output("image", "inspect", "--format", "{{json .Config.Labels}}", imageID); err == nil {
+		json.Unmarshal(output, &imageLabels)
+	}
+
+	for key, value := range labels {
+		if strings.HasPrefix(key, reservedLabelPrefix) {
+			delete(labels, key)
+		} else if inherited, ok := imageLabels[key]; ok && inherited == value {
+			delete(labels, key)
+		}
+	}
+	return labels, nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// defaultBackupReminderDays is how old a backup may get before list and show
+// warn about it, unless backup_reminder_days is set in ~/.go-db/config.json
+const defaultBackupReminderDays = 7
+
+// recordBackup notes a completed backup in the state file. Failing to record
+// it doesn't fail the backup itself.
+func recordBackup(containerName string) {
+	if err := utils.RecordBackup(containerName, time.Now()); err != nil {
+		printf("%s Warning: Could not record the backup time: %v\n", warn("⚠"), err)
+	}
+}
+
+// backupReminder returns the backup age list and show warn about, or 0 when
+// reminders are turned off with a negative backup_reminder_days
+func backupReminder() time.Duration {
+	days := defaultBackupReminderDays
+	if settings, err := utils.LoadSettings(); err == nil && settings.BackupReminderDays != 0 {
+		days = settings.BackupReminderDays
+	}
+	if days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// persistentData reports whether a container keeps its data in a volume or
+// bind mount. Backup reminders are pointless for tmpfs-backed test databases.
+func persistentData(containerName string) bool {
+	format := fmt.Sprintf(`{{range .Mounts}}{{if eq .Destination %q}}{{.Type}}{{end}}{{end}}`, dataDir)
+	output, err := dockerClient.Inspect(containerName, format)
+	if err != nil {
+		return false
+	}
+	kind := strings.TrimSpace(string(output))
+	return kind == "volume" || kind == "bind"
+}
+
+// lastBackups returns the recorded backup times by container name
+func lastBackups() map[string]time.Time {
+	backups := make(map[string]time.Time)
+	state, err := utils.LoadState()
+	if err != nil {
+		return backups
+	}
+	for name, db := range state.Databases {
+		if db.LastBackup != nil && !db.LastBackup.IsZero() {
+			backups[name] = *db.LastBackup
+		}
+	}
+	return backups
+}
+
+// formatLastBackup describes when a backup was taken, e.g. 3d 4h ago
+func formatLastBackup(last time.Time) string {
+	if last.IsZero() {
+		return "never"
+	}
+	return formatUptime(time.Since(last)) + " ago"
+}
+
+// backupOverdue reports whether a database should be backed up again
+func backupOverdue(last time.Time, reminder time.Duration) bool {
+	return reminder > 0 && (last.IsZero() || time.Since(last) > reminder)
+}
+
+// backupWarning is the reminder shown for a database that is due for a backup
+func backupWarning(name string, last time.Time, reminder time.Duration) string {
+	days := int(reminder.Hours() / 24)
+	if last.IsZero() {
+		return fmt.Sprintf("%s has never been backed up; run go-db backup %s", name, name)
+	}
+	return fmt.Sprintf("%s was last backed up more than %d days ago; run go-db backup %s", name, days, name)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+)
+
+// LogsOptions controls how Logs prints a container's logs
+type LogsOptions struct {
+	LocalTime bool // prefix each line with its timestamp converted to the host's timezone
+	Follow    bool // keep streaming new lines until interrupted
+	Tail      int  // number of lines from the end to show, negative for all
+}
+
+// localTimeFormat is how timestamps are shown with LocalTime
+const localTimeFormat = "2006-01-02 15:04:05.000 MST"
+
+// Logs prints the logs of a container
+func Logs(containerName string, opts LogsOptions) error {
+	if exists, _ := containerExists(containerName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
+	}
+
+	args := []string{"logs"}
+	if opts.LocalTime {
+		args = append(args, "-t")
+	}
+	if opts.Follow {
+		args = append(args, "-f")
+	}
+	if opts.Tail >= 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	args = append(args, containerName)
+
+	// Ctrl-C reaches docker logs as well, which ends the stream; stopping to
+	// follow is how the command is meant to end, so it's not an error
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	readErr := func(err error) error {
+		select {
+		case <-interrupted:
+			return nil
+		default:
+			return fmt.Errorf("Failed to read logs: %v", err)
+		}
+	}
+
+	if !opts.LocalTime {
+		if err := dockerClient.Stream(nil, os.Stdout, os.Stderr, args...); err != nil {
+			return readErr(err)
+		}
+		return nil
+	}
+
+	// docker logs replays the container's stdout and stderr separately; merge
+	// them into one stream so every line gets its timestamp rewritten
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(dockerClient.Stream(nil, pw, pw, args...))
+	}()
+
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fmt.Fprintln(os.Stdout, toLocalTime(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return readErr(err)
+	}
+	return nil
+}
+
+// toLocalTime rewrites the leading RFC3339 timestamp docker adds to a log line
+// in the host's timezone, leaving lines without one untouched
+func toLocalTime(line string) string {
+	stamp, rest, found := strings.Cut(line, " ")
+	if !found {
+		rest = ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return line
+	}
+	return t.Local().Format(localTimeFormat) + " " + rest
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceOptions selects the maintenance tasks to run
+type MaintenanceOptions struct {
+	Vacuum     bool // reclaim space from dead rows
+	VacuumFull bool // rewrite tables to return space to the OS (takes exclusive locks)
+	Analyze    bool // refresh planner statistics
+	Reindex    bool // rebuild all indexes of the database
+}
+
+// Maintenance runs routine VACUUM/ANALYZE/REINDEX tasks against a running container's database
+func Maintenance(containerName string, opts MaintenanceOptions) error {
+	if !opts.Vacuum && !opts.VacuumFull && !opts.Analyze && !opts.Reindex {
+		return fmt.Errorf("no maintenance task selected, use --vacuum, --vacuum-full, --analyze or --reindex")
+	}
+
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	tasks := []struct {
+		enabled bool
+		name    string
+		sql     string
+	}{
+		{opts.Vacuum, "VACUUM", "VACUUM"},
+		{opts.VacuumFull, "VACUUM FULL", "VACUUM FULL"},
+		{opts.Analyze, "ANALYZE", "ANALYZE"},
+		{opts.Reindex, "REINDEX", "REINDEX DATABASE " + quoteIdentifier(cfg.Database)},
+	}
+
+	if opts.VacuumFull {
+		printf("%s Warning: VACUUM FULL takes an exclusive lock on each table while it is rewritten\n", warn("⚠"))
+	}
+
+	for _, task := range tasks {
+		if !task.enabled {
+			continue
+		}
+
+		printf("%s Running %s on %s...\n", info("ℹ"), task.name, cfg.Database)
+		start := time.Now()
+		if _, err := psqlQuery(cfg, task.sql); err != nil {
+			return fmt.Errorf("%s failed: %v", task.name, err)
+		}
+		printf("%s %s completed in %s\n", success("✔"), task.name, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MetricsOptions controls the output format of Metrics
+type MetricsOptions struct {
+	JSON bool // print JSON instead of the Prometheus text exposition format
+}
+
+// metric is a metric family with its samples
+type metric struct {
+	Name    string   `json:"name"`
+	Help    string   `json:"help"`
+	Type    string   `json:"type"`
+	Samples []sample `json:"samples"`
+}
+
+// sample is a single labeled value of a metric
+type sample struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// statColumn describes a statistics view column exported as a metric
+type statColumn struct {
+	column string
+	kind   string // gauge or counter
+	help   string
+}
+
+// databaseColumns are the pg_stat_database columns exported per database
+var databaseColumns = []statColumn{
+	{"numbackends", "gauge", "Number of backends currently connected to the database"},
+	{"xact_commit", "counter", "Transactions committed"},
+	{"xact_rollback", "counter", "Transactions rolled back"},
+	{"blks_read", "counter", "Disk blocks read"},
+	{"blks_hit", "counter", "Disk blocks found in the buffer cache"},
+	{"tup_returned", "counter", "Rows returned by queries"},
+	{"tup_fetched", "counter", "Rows fetched by queries"},
+	{"tup_inserted", "counter", "Rows inserted"},
+	{"tup_updated", "counter", "Rows updated"},
+	{"tup_deleted", "counter", "Rows deleted"},
+	{"conflicts", "counter", "Queries canceled due to recovery conflicts"},
+	{"temp_files", "counter", "Temporary files created"},
+	{"temp_bytes", "counter", "Bytes written to temporary files"},
+	{"deadlocks", "counter", "Deadlocks detected"},
+}
+
+// bgwriterColumns are the background writer and checkpointer statistics.
+// PostgreSQL 17 moved the checkpoint counters to pg_stat_checkpointer, so the
+// query differs by version while the metric names stay the same.
+var bgwriterColumns = []statColumn{
+	{"checkpoints_timed", "counter", "Scheduled checkpoints performed"},
+	{"checkpoints_req", "counter", "Requested checkpoints performed"},
+	{"buffers_clean", "counter", "Buffers written by the background writer"},
+	{"maxwritten_clean", "counter", "Times the background writer stopped a cleaning scan for writing too many buffers"},
+	{"buffers_alloc", "counter", "Buffers allocated"},
+}
+
+// Metrics queries the statistics views of a running container and writes them
+// to w in the Prometheus text exposition format, or as JSON
+func Metrics(w io.Writer, containerName string, opts MetricsOptions) error {
+	cfg, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := collectMetrics(cfg)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Container string   `json:"container"`
+			Metrics   []metric `json:"metrics"`
+		}{containerName, metrics})
+	}
+	return writePrometheus(w, metrics)
+}
+
+// collectMetrics runs the statistics queries and converts the results to metrics
+func collectMetrics(cfg *Config) ([]metric, error) {
+	var metrics []metric
+	instanceLabels := map[string]string{"container": cfg.ContainerName}
+
+	// Per-database statistics
+	columns := make([]string, len(databaseColumns))
+	for i, c := range databaseColumns {
+		columns[i] = c.column
+	}
+	rows, err := queryRows(cfg, fmt.Sprintf(
+		"SELECT datname, %s FROM pg_stat_database WHERE datname IS NOT NULL ORDER BY datname",
+		strings.Join(columns, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query pg_stat_database: %v", err)
+	}
+	for i, c := range databaseColumns {
+		m := newMetric("pg_stat_database_"+c.column, c)
+		for _, row := range rows {
+			value, err := parseMetricValue(row, i+1)
+			if err != nil {
+				return nil, err
+			}
+			m.Samples = append(m.Samples, sample{
+				Labels: map[string]string{"container": cfg.ContainerName, "datname": row[0]},
+				Value:  value,
+			})
+		}
+		metrics = append(metrics, m)
+	}
+
+	// Background writer and checkpointer statistics
+	query := "SELECT checkpoints_timed, checkpoints_req, buffers_clean, maxwritten_clean, buffers_alloc FROM pg_stat_bgwriter"
+	if version, err := psqlQuery(cfg, "SHOW server_version_num"); err == nil {
+		if n, _ := strconv.Atoi(version); n >= 170000 {
+			query = `SELECT c.num_timed, c.num_requested, b.buffers_clean, b.maxwritten_clean, b.buffers_alloc
+				FROM pg_stat_checkpointer c, pg_stat_bgwriter b`
+		}
+	}
+	rows, err = queryRows(cfg, query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query pg_stat_bgwriter: %v", err)
+	}
+	for i, c := range bgwriterColumns {
+		m := newMetric("pg_stat_bgwriter_"+c.column, c)
+		if len(rows) > 0 {
+			value, err := parseMetricValue(rows[0], i)
+			if err != nil {
+				return nil, err
+			}
+			m.Samples = append(m.Samples, sample{Labels: instanceLabels, Value: value})
+		}
+		metrics = append(metrics, m)
+	}
+
+	// Client connections by state, and the limit they count against
+	rows, err = queryRows(cfg, `SELECT coalesce(state, 'unknown'), count(*)
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend'
+		GROUP BY 1 ORDER BY 1`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query pg_stat_activity: %v", err)
+	}
+	connections := newMetric("pg_stat_activity_count", statColumn{kind: "gauge", help: "Client connections by state"})
+	for _, row := range rows {
+		value, err := parseMetricValue(row, 1)
+		if err != nil {
+			return nil, err
+		}
+		connections.Samples = append(connections.Samples, sample{
+			Labels: map[string]string{"container": cfg.ContainerName, "state": row[0]},
+			Value:  value,
+		})
+	}
+	metrics = append(metrics, connections)
+
+	maxConnections, err := psqlQuery(cfg, "SHOW max_connections")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query max_connections: %v", err)
+	}
+	value, err := parseMetricValue([]string{maxConnections}, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit := newMetric("pg_settings_max_connections", statColumn{kind: "gauge", help: "Maximum number of concurrent connections"})
+	limit.Samples = []sample{{Labels: instanceLabels, Value: value}}
+	return append(metrics, limit), nil
+}
+
+// newMetric creates an empty metric; counters get the conventional _total suffix
+func newMetric(name string, c statColumn) metric {
+	if c.kind == "counter" {
+		name += "_total"
+	}
+	return metric{Name: name, Help: c.help, Type: c.kind}
+}
+
+// queryRows runs a query and splits psql's unaligned output into rows of fields
+func queryRows(cfg *Config, sql string) ([][]string, error) {
+	output, err := psqlQuery(cfg, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			rows = append(rows, strings.Split(line, "|"))
+		}
+	}
+	return rows, nil
+}
+
+// parseMetricValue parses the numeric field at index i of a row
+func parseMetricValue(row []string, i int) (float64, error) {
+	if i >= len(row) {
+		return 0, fmt.Errorf("unexpected statistics row: %q", strings.Join(row, "|"))
+	}
+	if row[i] == "" {
+		return 0, nil // NULL, e.g. a counter that was never reset
+	}
+	value, err := strconv.ParseFloat(row[i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid statistics value %q: %v", row[i], err)
+	}
+	return value, nil
+}
+
+// writePrometheus writes metrics in the Prometheus text exposition format
+func writePrometheus(w io.Writer, metrics []metric) error {
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.Name, m.Help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.Name, m.Type)
+		for _, s := range m.Samples {
+			fmt.Fprintf(&b, "%s%s %s\n", m.Name, formatLabels(s.Labels), strconv.FormatFloat(s.Value, 'f', -1, 64))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLabels renders a label set as {key="value",...} with sorted keys
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, key, escaper.Replace(labels[key]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+const (
+	pgbouncerImage       = "edoburu/pgbouncer"
+	pgbouncerDefaultPort = 6432
+	pgbouncerLabel       = "go-db.pgbouncer-for"
+	networkLabel         = "go-db.network-for"
+)
+
+// validatePoolMode checks that the PgBouncer pool mode is supported
+func validatePoolMode(mode string) error {
+	switch mode {
+	case "transaction", "session":
+		return nil
+	default:
+		return fmt.Errorf("invalid pool mode %q, expected transaction or session", mode)
+	}
+}
+
+// pgbouncerName returns the name of the PgBouncer sidecar for a database container
+func pgbouncerName(containerName string) string {
+	return containerName + "-pgbouncer"
+}
+
+// createSidecarNetwork creates a dedicated network shared by the database and its sidecar,
+// labelled so that it is removed together with the database
+func createSidecarNetwork(cfg *Config, network string) error {
+	output, err := dockerClient.CombinedOutput("network", "create",
+		"--label", fmt.Sprintf("%s=%s", networkLabel, cfg.ContainerName),
+		network)
+	if err != nil {
+		return fmt.Errorf("failed to create network %s: %v: %s", network, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// startPgBouncer runs a PgBouncer container on the database's network, pointed at the database.
+// It listens on cfg.PgBouncerPort when set, or on the first free port from 6432 otherwise.
+func startPgBouncer(cfg *Config) error {
+	if cfg.PgBouncerPort == "" {
+		port, err := utils.FindAvailablePort(pgbouncerDefaultPort, pgbouncerDefaultPort+defaultPortScanSize-1)
+		if err != nil {
+			return err
+		}
+		cfg.PgBouncerPort = fmt.Sprintf("%d", port)
+	}
+
+	args := []string{
+		"run",
+		"--name", pgbouncerName(cfg.ContainerName),
+		"--label", fmt.Sprintf("%s=%s", pgbouncerLabel, cfg.ContainerName),
+		"--network", cfg.Networks[0],
+		"-e", fmt.Sprintf("DB_HOST=%s", cfg.ContainerName),
+		"-e", "DB_PORT=5432",
+		"-e", fmt.Sprintf("DB_USER=%s", cfg.Username),
+		"-e", fmt.Sprintf("DB_PASSWORD=%s", cfg.Password),
+		"-e", fmt.Sprintf("DB_NAME=%s", cfg.Database),
+		"-e", fmt.Sprintf("POOL_MODE=%s", cfg.PoolMode),
+		"-e", "AUTH_TYPE=scram-sha-256",
+		"-e", fmt.Sprintf("LISTEN_PORT=%d", pgbouncerDefaultPort),
+		"-p", fmt.Sprintf("%s:%d", cfg.PgBouncerPort, pgbouncerDefaultPort),
+		"-d",
+		pgbouncerImage,
+	}
+	if output, err := dockerClient.CombinedOutput(args...); err != nil {
+		return fmt.Errorf("failed to start PgBouncer: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// pgbouncerPort returns the host port of the container's PgBouncer sidecar, if it has one
+func pgbouncerPort(containerName string) string {
+	output, err := dockerClient.PS("{{.Names}}", fmt.Sprintf("label=%s=%s", pgbouncerLabel, containerName))
+	if err != nil {
+		return ""
+	}
+
+	name := strings.TrimSpace(string(output))
+	if name == "" {
+		return ""
+	}
+	format := fmt.Sprintf(`{{range $p, $conf := .HostConfig.PortBindings}}{{if eq $p "%d/tcp"}}{{range $conf}}{{.HostPort}}{{end}}{{end}}{{end}}`, pgbouncerDefaultPort)
+	port, err := dockerClient.Inspect(name, format)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(port))
+}
+
+// renameSidecars moves the PgBouncer sidecar of a renamed database, and the
+// network go-db created for it, over to the new name. Docker can change
+// neither labels nor environment, so both are recreated; the sidecar keeps
+// its pool mode and host port.
+func renameSidecars(oldName, newName string) error {
+	output, err := dockerClient.PS("{{.Names}}", fmt.Sprintf("label=%s=%s", pgbouncerLabel, oldName))
+	if err != nil {
+		return fmt.Errorf("failed to look for a PgBouncer sidecar: %v", err)
+	}
+	sidecar := strings.TrimSpace(string(output))
+	if sidecar == "" {
+		return nil
+	}
+
+	env, err := containerEnv(sidecar)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %v", sidecar, err)
+	}
+	output, err = dockerClient.Inspect(sidecar, "{{range $name, $_ := .NetworkSettings.Networks}}{{$name}} {{end}}")
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %v", sidecar, err)
+	}
+	networks := strings.Fields(string(output))
+	if len(networks) == 0 {
+		return fmt.Errorf("PgBouncer sidecar %s is not on any network", sidecar)
+	}
+
+	cfg, err := inspectConfig(newName)
+	if err != nil {
+		return err
+	}
+	cfg.PoolMode = env["POOL_MODE"]
+	cfg.PgBouncerPort = pgbouncerPort(oldName)
+	cfg.Networks = networks[:1]
+
+	// A network go-db created is named and labelled after the database, so it
+	// is replaced; a network the user chose is kept
+	oldNetwork := ""
+	label, err := dockerClient.Output("network", "inspect", "--format", fmt.Sprintf("{{index .Labels %q}}", networkLabel), networks[0])
+	if err == nil && strings.TrimSpace(string(label)) == oldName {
+		oldNetwork = networks[0]
+		cfg.Networks = []string{newName + "-net"}
+		if err := createSidecarNetwork(cfg, cfg.Networks[0]); err != nil {
+			return err
+		}
+		if output, err := dockerClient.CombinedOutput("network", "connect", cfg.Networks[0], newName); err != nil {
+			return fmt.Errorf("failed to connect %s to %s: %v: %s", newName, cfg.Networks[0], err, strings.TrimSpace(string(output)))
+		}
+		if output, err := dockerClient.CombinedOutput("network", "disconnect", oldNetwork, newName); err != nil {
+			return fmt.Errorf("failed to disconnect %s from %s: %v: %s", newName, oldNetwork, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	if output, err := dockerClient.CombinedOutput("rm", "-f", sidecar); err != nil {
+		return fmt.Errorf("failed to remove %s: %v: %s", sidecar, err, strings.TrimSpace(string(output)))
+	}
+	if oldNetwork != "" {
+		if err := dockerClient.Run("network", "rm", oldNetwork); err != nil {
+			printf("%s Warning: Could not remove network %s: %v\n", warn("⚠"), oldNetwork, err)
+		}
+	}
+	if err := startPgBouncer(cfg); err != nil {
+		return err
+	}
+	printf("%s PgBouncer sidecar moved to %s on port %s\n", success("✔"), pgbouncerName(newName), cfg.PgBouncerPort)
+	return nil
+}
+
+// removeSidecars removes the PgBouncer containers and networks registered for a database container
+func removeSidecars(containerName string) {
+	output, err := dockerClient.Output("ps", "-aq",
+		"--filter", fmt.Sprintf("label=%s=%s", pgbouncerLabel, containerName))
+	if err == nil {
+		for _, id := range strings.Fields(string(output)) {
+			if err := dockerClient.Run("rm", "-f", id); err != nil {
+				printf("%s Warning: Could not remove PgBouncer sidecar: %v\n", warn("⚠"), err)
+				continue
+			}
+			printf("%s PgBouncer sidecar removed\n", success("✔"))
+		}
+	}
+
+	output, err = dockerClient.Output("network", "ls", "-q",
+		"--filter", fmt.Sprintf("label=%s=%s", networkLabel, containerName))
+	if err == nil {
+		for _, id := range strings.Fields(string(output)) {
+			if err := dockerClient.Run("network", "rm", id); err != nil {
+				printf("%s Warning: Could not remove network: %v\n", warn("⚠"), err)
+			}
+		}
+	}
+}
+
+// ensureNetworks creates the networks that don't exist yet as bridge networks
+// and returns the ones it created, for --network-create
+func ensureNetworks(networks []string) ([]string, error) {
+	var created []string
+	for _, network := range networks {
+		if dockerClient.Run("network", "inspect", network) == nil {
+			continue
+		}
+		output, err := dockerClient.CombinedOutput("network", "create", "--driver", "bridge", network)
+		if err != nil {
+			return created, fmt.Errorf("failed to create network %s: %v: %s", network, err, strings.TrimSpace(string(output)))
+		}
+		created = append(created, network)
+	}
+	return created, nil
+}
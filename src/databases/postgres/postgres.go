@@ -1,16 +1,29 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
-	"os/exec"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/docker"
+	dberrors "github.com/awade12/go-db/src/errors"
 	"github.com/awade12/go-db/src/utils"
 	"github.com/schollz/progressbar/v3"
 )
 
+// Colors decorate printed output only; returned errors stay plain text so they
+// can be logged and serialized, and the CLI colors them when printing
 var (
 	success  = utils.Success
 	info     = utils.Info
@@ -18,46 +31,207 @@ var (
 	errColor = utils.ErrColor
 )
 
+// dockerClient runs every container command of the package, streaming ones
+// such as backups and psql sessions included; SetDockerClient swaps it, e.g.
+// for a fake in tests
+var dockerClient docker.DockerClient = docker.ExecClient{}
+
+// SetDockerClient replaces the client container commands go through
+func SetDockerClient(client docker.DockerClient) {
+	dockerClient = client
+}
+
+// printf writes human-readable output, which is discarded in machine-readable modes
+func printf(format string, a ...interface{}) {
+	fmt.Fprintf(utils.Output, format, a...)
+}
+
+// Defaults from the postgres entry of the database type registry
+var (
+	defaultPostgresVersion = databases.Postgres.DefaultVersion
+	defaultPort            = databases.Postgres.DefaultPortString()
+)
+
 const (
-	defaultPostgresVersion = "15"
-	defaultPort            = "5432"
+	defaultPortScanSize = 100
+
+	// Healthcheck defaults tuned for PostgreSQL startup
+	defaultHealthInterval    = "5s"
+	defaultHealthTimeout     = "5s"
+	defaultHealthRetries     = 5
+	defaultHealthStartPeriod = "10s"
+	defaultConnectionTimeout = "5s"
+
+	// Readiness polling: the whole wait, and the longest pause between polls
+	defaultReadinessTimeout = 30 * time.Second
+	maxReadinessInterval    = 2 * time.Second
+
+	// DefaultShmSize replaces docker's 64MB /dev/shm, which parallel query
+	// workers run out of
+	DefaultShmSize = "256m"
 )
 
-// findAvailablePort finds an available port starting from the given port
-func findAvailablePort(startPort int) (int, error) {
-	for port := startPort; port < startPort+100; port++ {
-		addr := fmt.Sprintf(":%d", port)
-		listener, err := net.Listen("tcp", addr)
-		if err == nil {
-			listener.Close()
-			return port, nil
+// allocatePort checks that the requested port is free, moving on to the next
+// free one within --port-range N ports (default 100) unless --exact-port is set
+func allocatePort(cfg *Config) error {
+	requested, err := strconv.Atoi(cfg.Port)
+	if err != nil || requested < 1 || requested > 65535 {
+		return fmt.Errorf("invalid port %q, expected a number between 1 and 65535", cfg.Port)
+	}
+
+	count := 1
+	if !cfg.ExactPort {
+		if count, err = portScanCount(cfg.PortRange); err != nil {
+			return err
 		}
 	}
-	return 0, fmt.Errorf("no available ports found in range %d-%d", startPort, startPort+100)
+	port, err := databases.Postgres.FindPort(requested, count)
+	if err != nil {
+		if cfg.ExactPort {
+			return dberrors.Newf(dberrors.ErrPortUnavailable, "Port %d is already in use by %s (--exact-port disables picking another one)",
+				requested, databases.DescribePortHolder(requested))
+		}
+		return dberrors.Wrapf(dberrors.ErrPortUnavailable, err, "Failed to find available port")
+	}
+	if port != requested {
+		printf("%s Port %d is held by %s, using port %d instead\n",
+			info("ℹ"), requested, databases.DescribePortHolder(requested), port)
+	}
+	cfg.Port = strconv.Itoa(port)
+	return nil
+}
+
+// portScanCount parses --port-range given as a number of ports to try
+func portScanCount(portRange string) (int, error) {
+	if portRange == "" {
+		return defaultPortScanSize, nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(portRange))
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("invalid port range %q, expected a number of ports (e.g., 20) or start-end (e.g., 6000-6100)", portRange)
+	}
+	return count, nil
+}
+
+// parsePortRange parses a port range of the form "6000-6100"
+func parsePortRange(portRange string) (int, int, error) {
+	parts := strings.SplitN(portRange, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid port range %q, expected format start-end (e.g., 6000-6100)", portRange)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start port in range %q: %v", portRange, err)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end port in range %q: %v", portRange, err)
+	}
+
+	if start < 1 || end > 65535 || start > end {
+		return 0, 0, fmt.Errorf("invalid port range %q, ports must satisfy 1 <= start <= end <= 65535", portRange)
+	}
+	return start, end, nil
 }
 
-// Config holds PostgreSQL configuration options
+// prefixLabel records the namespace prefix a container was created under
+const prefixLabel = "go-db.prefix"
+
+// WithPrefix prepends the namespace prefix to a container name unless it is already present
+func WithPrefix(prefix, name string) string {
+	if prefix == "" || strings.HasPrefix(name, prefix) {
+		return name
+	}
+	return prefix + name
+}
+
+// setupStep is a single named step of the container setup shown in the progress bar
+type setupStep struct {
+	name string
+	fn   func() error
+}
+
+// Config holds PostgreSQL configuration options. The JSON keys match the
+// create-custom flag names, so a config file reads like a list of flags.
 type Config struct {
-	Version       string
-	Port          string
-	Password      string
-	ContainerName string // required: name of the container
-	Username      string
-	Database      string
-	Volume        string            // for persistent storage
-	Memory        string            // memory limit
-	CPU           string            // CPU limit
-	Replicas      int               // number of replicas for HA
-	InitScripts   []string          // paths to initialization SQL scripts
-	Environment   map[string]string // additional environment variables
-	Networks      []string          // docker networks to join
-	ExtraMounts   []string          // additional volume mounts
-	SSLMode       string            // SSL mode (disable, require, verify-ca, verify-full)
-	SSLCert       string            // path to SSL certificate
-	SSLKey        string            // path to SSL key
-	SSLRootCert   string            // path to SSL root certificate
-	Timezone      string            // container timezone
-	Locale        string            // database locale
+	Version                string            `json:"version"`
+	Port                   string            `json:"port"`
+	Password               string            `json:"password"`
+	PasswordGenerated      bool              `json:"-"`    // the password was generated rather than given
+	ContainerName          string            `json:"name"` // required: name of the container
+	Username               string            `json:"user"`
+	Database               string            `json:"db"`
+	Volume                 string            `json:"volume"`                          // for persistent storage: a host path (bind mount) or a docker volume name
+	VolumeDriver           string            `json:"volume-driver"`                   // driver a new named volume is created with, e.g. an NFS plugin
+	RequireEncryptedVolume bool              `json:"require-encrypted-volume"`        // refuse to create unless the data is stored on an encrypted device (Linux only)
+	Memory                 string            `json:"memory"`                          // memory limit
+	MemorySwap             string            `json:"memory-swap"`                     // memory plus swap limit, -1 for unlimited swap
+	ShmSize                string            `json:"shm-size"`                        // size of /dev/shm (default 256m)
+	StopSignal             string            `json:"stop-signal"`                     // signal docker stop sends: SIGTERM (smart), SIGINT (fast) or SIGQUIT (immediate shutdown)
+	RestartPolicy          string            `json:"restart"`                         // docker restart policy: no, on-failure, always or unless-stopped
+	CPU                    string            `json:"cpu"`                             // CPU limit
+	Replicas               int               `json:"replicas"`                        // number of replicas for HA
+	InitScripts            []string          `json:"init-script"`                     // paths to initialization SQL scripts
+	InitOrder              []string          `json:"init-order"`                      // init script file names in the order they should run
+	StartupScripts         []string          `json:"startup-script"`                  // SQL scripts run with psql after every create or start
+	Environment            map[string]string `json:"environment"`                     // additional environment variables
+	Networks               []string          `json:"network"`                         // docker networks to join
+	NetworkCreate          bool              `json:"network-create"`                  // create the networks that don't exist yet
+	ExtraMounts            []string          `json:"extra-mounts"`                    // additional volume mounts
+	SSLMode                string            `json:"ssl-mode"`                        // SSL mode (disable, require, verify-ca, verify-full)
+	SSLCert                string            `json:"ssl-cert"`                        // path to SSL certificate
+	SSLKey                 string            `json:"ssl-key"`                         // path to SSL key
+	SSLRootCert            string            `json:"ssl-root-cert"`                   // path to SSL root certificate
+	SSLGen                 bool              `json:"ssl-gen"`                         // generate a self-signed certificate under ~/.go-db/certs/<name>
+	OutputDir              string            `json:"output-dir"`                      // directory for generated artifacts such as certificates
+	EnvFile                string            `json:"env-file"`                        // .env file the connection details are written to
+	Force                  bool              `json:"force"`                           // replace variables EnvFile already defines
+	Timezone               string            `json:"timezone"`                        // container timezone
+	Locale                 string            `json:"locale"`                          // database locale
+	PortRange              string            `json:"port-range"`                      // port range to allocate from, e.g. "6000-6100", or how many ports after Port to try
+	ExactPort              bool              `json:"exact-port"`                      // fail instead of moving on when Port is taken
+	ReadyCommand           string            `json:"ready-cmd"`                       // readiness probe run inside the container (default: pg_isready)
+	PreCreateHook          string            `json:"pre-create-hook"`                 // local shell command run before the container is created
+	PostCreateHook         string            `json:"post-create-hook"`                // local shell command run after the container is ready
+	JSONLogs               bool              `json:"json-logs"`                       // emit structured JSON events to stderr
+	ReuseExisting          bool              `json:"reuse-existing"`                  // treat an existing container with the same name as success
+	Reconcile              bool              `json:"reconcile"`                       // compare an existing container with the requested configuration
+	ForceRecreate          bool              `json:"force-recreate-on-config-change"` // recreate an existing container whose configuration has drifted
+	CopyFrom               string            `json:"copy-from"`                       // existing container whose data volume is cloned into the new one
+	WithPgBouncer          bool              `json:"with-pgbouncer"`                  // start a PgBouncer sidecar in front of the database
+	PoolMode               string            `json:"pool-mode"`                       // PgBouncer pool mode (transaction, session)
+	PgBouncerPort          string            `json:"-"`                               // host port of the PgBouncer sidecar, set once it is running
+	SkipPull               bool              `json:"skip-pull"`                       // assume the image is present locally and never pull it
+	Image                  string            `json:"image"`                           // image repository, e.g. registry.example.com/team/postgres (default: postgres)
+	RegistryAuth           string            `json:"registry-auth"`                   // user:password to log in to the image's registry before pulling
+	Prefix                 string            `json:"prefix"`                          // namespace prepended to the container name
+	NoNameValidation       bool              `json:"no-name-validation"`              // skip checking the container name against docker's naming rules
+	HealthInterval         string            `json:"health-interval"`                 // time between docker health checks
+	HealthTimeout          string            `json:"health-timeout"`                  // time before a single health check is considered failed
+	HealthRetries          int               `json:"health-retries"`                  // consecutive failures before the container is unhealthy
+	HealthStartPeriod      string            `json:"health-start-period"`             // grace period during startup before failures count
+	WaitForHealthy         bool              `json:"wait-for-healthy"`                // wait for docker's health status instead of probing with pg_isready
+	ConnectionTimeout      string            `json:"connection-timeout"`              // deadline for each readiness probe attempt
+	ReadinessTimeout       time.Duration     `json:"wait-timeout"`                    // how long to wait for the server to accept connections
+	ReplicaOf              string            `json:"replica-of"`                      // external primary (host:port) to run as a streaming standby of
+	ReplicationUser        string            `json:"replication-user"`                // role pg_basebackup and the standby connect to the primary as
+	ReplicationPassword    string            `json:"replication-password"`            // password of the replication role
+	LogQueries             bool              `json:"log-queries"`                     // log every statement and its duration (development only)
+	PostgresParams         map[string]string `json:"pg-param"`                        // extra postgresql.conf parameters passed as -c key=value
+	Labels                 map[string]string `json:"label"`                           // extra docker labels set on the container
+	MaxWALSize             string            `json:"max-wal-size"`                    // max_wal_size server setting, e.g. 2GB
+	CheckpointTimeout      string            `json:"checkpoint-timeout"`              // checkpoint_timeout server setting, e.g. 15min
+	MemAuto                bool              `json:"mem-auto"`                        // size the memory limit from the host's RAM
+	MemFraction            float64           `json:"mem-fraction"`                    // share of the host's RAM used by MemAuto (default 0.25)
+	SharedBuffers          string            `json:"shared-buffers"`                  // shared_buffers server setting; MemAuto sets it to a quarter of the limit
+	TestMode               bool              `json:"test"`                            // disposable test database: random high port, tmpfs, trust auth, --rm
+	NoFsync                bool              `json:"no-fsync"`                        // fsync, full_page_writes and synchronous_commit off, for throwaway databases
+	Ephemeral              bool              `json:"ephemeral"`                       // keep the data directory on tmpfs
+	AutoRemove             bool              `json:"auto-remove"`                     // remove the container as soon as it stops
+	TrustAuth              bool              `json:"trust-auth"`                      // accept connections without a password
+	PreferIPv6             bool              `json:"prefer-ipv6"`                     // show the host's IPv6 address in the main connection string
+	NoDefaultDB            bool              `json:"no-default-db"`                   // point POSTGRES_DB at postgres so only the built-in database exists
 }
 
 func DefaultConfig(name string) *Config {
@@ -75,9 +249,94 @@ func DefaultConfig(name string) *Config {
 		SSLMode:       "disable",
 		Timezone:      "UTC",
 		Locale:        "en_US.utf8",
+
+		HealthInterval:    defaultHealthInterval,
+		HealthTimeout:     defaultHealthTimeout,
+		HealthRetries:     defaultHealthRetries,
+		HealthStartPeriod: defaultHealthStartPeriod,
+		ConnectionTimeout: defaultConnectionTimeout,
+		ReadinessTimeout:  defaultReadinessTimeout,
+		ShmSize:           DefaultShmSize,
 	}
 }
 
+// Validate checks the configuration before anything is created. Every
+// problem found is reported, joined into one error.
+func (c *Config) Validate() error {
+	var problems []error
+	if c.ContainerName == "" {
+		problems = append(problems, fmt.Errorf("container name is required"))
+	} else if !c.NoNameValidation {
+		problems = append(problems, databases.ValidateContainerName(c.ContainerName))
+	}
+	problems = append(problems,
+		validateHealthcheck(c),
+		validateTuning(c),
+		validateReplica(c),
+		databases.ValidateTimezone(c.Timezone),
+		validateImage(c.Image),
+		validateRegistryAuth(c.RegistryAuth),
+		validateStopSignal(c),
+		validateRestartPolicy(c),
+		validatePostgresParams(c),
+		validateLabels(c),
+		validateSSL(c),
+	)
+	if c.VolumeDriver != "" && !isNamedVolume(c.Volume) {
+		problems = append(problems, fmt.Errorf("--volume-driver needs --volume to name a docker volume, not a host path"))
+	}
+	if c.ReadinessTimeout < 0 {
+		problems = append(problems, fmt.Errorf("--wait-timeout must not be negative"))
+	}
+	if c.ExactPort && (c.PortRange != "" || (c.TestMode && c.Port == defaultPort)) {
+		problems = append(problems, fmt.Errorf("--exact-port cannot be combined with --port-range, or with --test unless --port is given"))
+	}
+	return errors.Join(problems...)
+}
+
+// stopSignals maps the signals --stop-signal accepts to the PostgreSQL
+// shutdown mode each one triggers
+var stopSignals = map[string]string{
+	"SIGTERM": "smart: waits for every client to disconnect",
+	"SIGINT":  "fast: rolls back open transactions and disconnects clients",
+	"SIGQUIT": "immediate: exits without a shutdown checkpoint, recovery runs on the next start",
+	"SIGKILL": "killed: no clean shutdown at all, recovery runs on the next start",
+}
+
+// validateStopSignal checks --stop-signal and normalizes it to its SIG-prefixed
+// upper-case name, e.g. int becomes SIGINT
+func validateStopSignal(cfg *Config) error {
+	if cfg.StopSignal == "" {
+		return nil
+	}
+	name := strings.ToUpper(cfg.StopSignal)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if _, ok := stopSignals[name]; !ok {
+		return fmt.Errorf("invalid --stop-signal %q, expected SIGTERM, SIGINT, SIGQUIT or SIGKILL", cfg.StopSignal)
+	}
+	cfg.StopSignal = name
+	return nil
+}
+
+// validateRestartPolicy checks --restart against docker's policies. Disposable
+// containers are removed when they stop, which docker refuses to combine with
+// restarting them.
+func validateRestartPolicy(cfg *Config) error {
+	switch cfg.RestartPolicy {
+	case "", "no":
+		return nil
+	case "on-failure", "always", "unless-stopped":
+	default:
+		return fmt.Errorf("invalid --restart %q, expected no, on-failure, always or unless-stopped", cfg.RestartPolicy)
+	}
+	if cfg.AutoRemove || cfg.TestMode {
+		return fmt.Errorf("--restart %s cannot be combined with --test or auto-remove", cfg.RestartPolicy)
+	}
+	return nil
+}
+
 // Create sets up a new PostgreSQL database instance using Docker with default settings
 func Create(name string) error {
 	return CreateWithConfig(DefaultConfig(name))
@@ -86,59 +345,202 @@ func Create(name string) error {
 // CreateWithConfig sets up a new PostgreSQL instance with custom configuration
 func CreateWithConfig(cfg *Config) error {
 	if cfg == nil {
-		return fmt.Errorf("%s configuration cannot be nil", errColor("✘"))
+		return fmt.Errorf("configuration cannot be nil")
+	}
+
+	if cfg.ContainerName != "" {
+		cfg.ContainerName = WithPrefix(cfg.Prefix, cfg.ContainerName)
 	}
 
-	if cfg.ContainerName == "" {
-		return fmt.Errorf("%s container name is required", errColor("✘"))
+	if cfg.NoDefaultDB {
+		cfg.Database = "postgres"
+	}
+
+	if cfg.TestMode {
+		if err := applyTestPreset(cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := applyMemAuto(cfg); err != nil {
+		return err
+	}
+
+	if !isNamedVolume(cfg.Volume) {
+		volume, err := utils.ExpandHome(cfg.Volume)
+		if err != nil {
+			return fmt.Errorf("invalid --volume: %v", err)
+		}
+		cfg.Volume = volume
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := checkEnvFile(cfg); err != nil {
+		return err
+	}
+
+	if cfg.SSLGen {
+		if err := generateCertificate(cfg); err != nil {
+			return fmt.Errorf("Failed to generate SSL certificate: %v", err)
+		}
 	}
 
-	fmt.Printf("%s Starting PostgreSQL setup for %s...\n", info("ℹ"), cfg.ContainerName)
+	printf("%s Starting PostgreSQL setup for %s...\n", info("ℹ"), cfg.ContainerName)
 
-	// Check if Docker is installed
-	if _, err := exec.LookPath("docker"); err != nil {
-		return fmt.Errorf("%s Docker is not installed: %v", errColor("✘"), err)
+	// Check that the container runtime is installed
+	if err := utils.CheckRuntime(); err != nil {
+		return err
 	}
 
 	// Check if container already exists
-	if exists, _ := containerExists(cfg.ContainerName); exists {
-		return fmt.Errorf("%s Container %s already exists. Use 'go-db remove %s' to remove it first",
-			errColor("✘"), cfg.ContainerName, cfg.ContainerName)
+	if exists, running := containerExists(cfg.ContainerName); exists && cfg.Reconcile {
+		if recreate, err := reconcile(cfg, running); err != nil || !recreate {
+			return err
+		}
+	} else if exists {
+		if cfg.ReuseExisting {
+			return reuseContainer(cfg, running)
+		}
+		return dberrors.Newf(dberrors.ErrContainerExists, "Container %s already exists. Use 'go-db remove %s' to remove it first",
+			cfg.ContainerName, cfg.ContainerName)
 	}
 
-	// Find available port if default is taken
-	if cfg.Port == defaultPort {
-		port, err := findAvailablePort(5432)
+	// Allocate from the requested port range, or find an available port if default is taken.
+	// Test databases get a random high port unless one was asked for.
+	if cfg.TestMode && cfg.PortRange == "" && cfg.Port == defaultPort {
+		port, err := randomHighPort()
 		if err != nil {
-			return fmt.Errorf("%s Failed to find available port: %v", errColor("✘"), err)
+			return dberrors.Wrapf(dberrors.ErrPortUnavailable, err, "Failed to find available port")
 		}
 		cfg.Port = fmt.Sprintf("%d", port)
-		if cfg.Port != defaultPort {
-			fmt.Printf("%s Port %s was taken, using port %s instead\n", info("ℹ"), defaultPort, cfg.Port)
+	} else if strings.Contains(cfg.PortRange, "-") {
+		start, end, err := parsePortRange(cfg.PortRange)
+		if err != nil {
+			return err
 		}
+		port, err := utils.FindAvailablePort(start, end)
+		if err != nil {
+			return dberrors.Wrapf(dberrors.ErrPortUnavailable, err, "Failed to find available port")
+		}
+		cfg.Port = fmt.Sprintf("%d", port)
+		printf("%s Using port %s from range %s\n", info("ℹ"), cfg.Port, cfg.PortRange)
+	} else if err := allocatePort(cfg); err != nil {
+		return err
 	}
 
-	steps := []struct {
-		name string
-		fn   func() error
-	}{
-		{
+	if cfg.CopyFrom != "" {
+		if err := prepareCopy(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.ReplicaOf != "" {
+		if err := prepareReplica(cfg); err != nil {
+			return err
+		}
+	}
+	if cfg.RequireEncryptedVolume {
+		if err := checkEncryptedVolume(cfg); err != nil {
+			return err
+		}
+	}
+
+	var sidecarNetwork string
+	if cfg.WithPgBouncer {
+		if err := validatePoolMode(cfg.PoolMode); err != nil {
+			return err
+		}
+		if len(cfg.Networks) == 0 {
+			sidecarNetwork = cfg.ContainerName + "-net"
+			cfg.Networks = []string{sidecarNetwork}
+		}
+	}
+
+	if err := resolveStartupScripts(cfg); err != nil {
+		return err
+	}
+
+	if cfg.LogQueries {
+		printf("%s Warning: --log-queries logs every statement; this is verbose and meant for development only\n", warn("⚠"))
+	}
+	if cfg.StopSignal != "" {
+		printf("%s Stopping sends %s, a %s shutdown\n", info("ℹ"), cfg.StopSignal, stopSignals[cfg.StopSignal])
+	}
+	if cfg.NoFsync && !cfg.Ephemeral {
+		printf("%s %s\n", warn("⚠"), errColor("Warning: --no-fsync turns off crash safety; expect data loss or a corrupt database if the server or host crashes"))
+	}
+
+	if cfg.PreCreateHook != "" {
+		printf("%s Running pre-create hook...\n", info("ℹ"))
+		if err := runHook(cfg.PreCreateHook, cfg); err != nil {
+			emitEvent(cfg, "create", EventError, "pre-create hook", err.Error())
+			return fmt.Errorf("Pre-create hook failed: %v", err)
+		}
+	}
+
+	var steps []setupStep
+	if !cfg.SkipPull {
+		steps = append(steps, setupStep{
 			name: "Pulling PostgreSQL image",
 			fn: func() error {
-				// Only pull if image doesn't exist
-				if out, _ := exec.Command("docker", "images", "-q", fmt.Sprintf("postgres:%s", cfg.Version)).Output(); len(out) == 0 {
-					cmd := exec.Command("docker", "pull", fmt.Sprintf("postgres:%s", cfg.Version))
-					return cmd.Run()
-				}
-				return nil
+				return pullImage(cfg)
 			},
-		},
+		})
+	}
+
+	if cfg.CopyFrom != "" {
+		steps = append(steps, setupStep{
+			name: fmt.Sprintf("Copying data from %s", cfg.CopyFrom),
+			fn: func() error {
+				return copyDataVolume(cfg.CopyFrom, cfg.Volume, cfg.VolumeDriver)
+			},
+		})
+	}
+
+	if cfg.ReplicaOf != "" {
+		steps = append(steps, setupStep{
+			name: fmt.Sprintf("Copying base backup from %s", cfg.ReplicaOf),
+			fn: func() error {
+				return baseBackup(cfg)
+			},
+		})
+	}
+
+	if sidecarNetwork != "" {
+		steps = append(steps, setupStep{
+			name: fmt.Sprintf("Creating network %s", sidecarNetwork),
+			fn: func() error {
+				return createSidecarNetwork(cfg, sidecarNetwork)
+			},
+		})
+	}
+
+	var createdNetworks []string
+	if cfg.NetworkCreate && len(cfg.Networks) > 0 && sidecarNetwork == "" {
+		steps = append(steps, setupStep{
+			name: "Creating networks",
+			fn: func() (err error) {
+				createdNetworks, err = ensureNetworks(cfg.Networks)
+				return err
+			},
+		})
+	}
+
+	steps = append(steps, []setupStep{
 		{
 			name: "Creating container",
 			fn: func() error {
-				args := buildDockerArgs(cfg)
-				cmd := exec.Command("docker", args...)
-				return cmd.Run()
+				if cfg.SkipPull {
+					image := imageRef(cfg)
+					if err := dockerClient.Run("image", "inspect", image); err != nil {
+						return fmt.Errorf("image %s is not present locally and --skip-pull is set; load or pull it first", image)
+					}
+				}
+				if err := ensureVolume(cfg.Volume, cfg.VolumeDriver); err != nil {
+					return err
+				}
+				return dockerClient.Run(buildDockerArgs(cfg)...)
 			},
 		},
 		{
@@ -147,9 +549,19 @@ func CreateWithConfig(cfg *Config) error {
 				return waitForPostgres(cfg)
 			},
 		},
+	}...)
+
+	if cfg.WithPgBouncer {
+		steps = append(steps, setupStep{
+			name: "Starting PgBouncer",
+			fn: func() error {
+				return startPgBouncer(cfg)
+			},
+		})
 	}
 
 	bar := progressbar.NewOptions(len(steps),
+		progressbar.OptionSetWriter(utils.Output),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(30),
@@ -164,16 +576,50 @@ func CreateWithConfig(cfg *Config) error {
 
 	for _, step := range steps {
 		bar.Describe(fmt.Sprintf("[cyan]%s[reset]", step.name))
+		emitEvent(cfg, "create", EventStepStarted, step.name, "")
 		if err := step.fn(); err != nil {
-			fmt.Printf("\n%s %s failed: %v\n", errColor("✘"), step.name, err)
+			emitEvent(cfg, "create", EventError, step.name, err.Error())
+			printf("\n%s %s failed: %v\n", errColor("✘"), step.name, err)
 			return fmt.Errorf("failed during %s: %v", step.name, err)
 		}
+		emitEvent(cfg, "create", EventStepCompleted, step.name, "")
 		bar.Add(1)
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	fmt.Printf("\n%s PostgreSQL container created successfully!\n", success("✔"))
-	printConnectionDetails(cfg)
+	emitEvent(cfg, "create", EventReady, "", fmt.Sprintf("listening on port %s", cfg.Port))
+	printf("\n%s PostgreSQL container created successfully!\n", success("✔"))
+	for _, network := range createdNetworks {
+		printf("%s Created network %s; it is not removed with the container (%s network rm %s)\n",
+			info("ℹ"), network, utils.ContainerRuntime(), network)
+	}
+
+	if len(cfg.StartupScripts) > 0 {
+		if err := runStartupScripts(cfg); err != nil {
+			return err
+		}
+	}
+
+	if cfg.PostCreateHook != "" {
+		printf("%s Running post-create hook...\n", info("ℹ"))
+		if err := runHook(cfg.PostCreateHook, cfg); err != nil {
+			printf("%s Warning: Post-create hook failed: %v\n", warn("⚠"), err)
+		}
+	}
+
+	role := RolePrimary
+	if cfg.ReplicaOf != "" {
+		role = RoleReplica
+	}
+	printConnectionDetails(cfg, role)
+
+	if cfg.EnvFile != "" {
+		if err := WriteEnvFile(cfg, cfg.EnvFile); err != nil {
+			printf("%s Warning: Could not write %s: %v\n", warn("⚠"), cfg.EnvFile, err)
+		} else {
+			printf("\n%s Connection details written to %s\n", success("✔"), cfg.EnvFile)
+		}
+	}
 
 	return nil
 }
@@ -184,13 +630,62 @@ func buildDockerArgs(cfg *Config) []string {
 		"--name", cfg.ContainerName,
 		"-e", fmt.Sprintf("POSTGRES_PASSWORD=%s", cfg.Password),
 		"-e", fmt.Sprintf("POSTGRES_USER=%s", cfg.Username),
-		"-e", fmt.Sprintf("POSTGRES_DB=%s", cfg.Database),
 		"-e", fmt.Sprintf("TZ=%s", cfg.Timezone),
 		"-e", fmt.Sprintf("LANG=%s", cfg.Locale),
-		"-p", fmt.Sprintf("%s:5432", cfg.Port),
+		"-p", databases.Postgres.PortMapping(cfg.Port),
 		"-d",
 	}
 
+	// The image defaults POSTGRES_DB to the user name, so --no-default-db
+	// (Database is then "postgres") still has to set it to skip the extra database
+	args = append(args, "-e", fmt.Sprintf("POSTGRES_DB=%s", cfg.Database))
+
+	// Let docker track the container's health
+	args = append(args, healthcheckArgs(cfg)...)
+
+	// Mark the container as go-db's, for list, and add the user's labels
+	args = append(args, databases.Postgres.LabelArgs()...)
+	args = append(args, labelArgs(cfg)...)
+
+	// Record the namespace the container was created under
+	if cfg.Prefix != "" {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", prefixLabel, cfg.Prefix))
+	}
+
+	// Record the startup scripts so that start runs them again
+	if len(cfg.StartupScripts) > 0 {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", startupScriptsLabel, strings.Join(cfg.StartupScripts, ",")))
+	}
+
+	// Choose the shutdown mode docker stop triggers
+	if cfg.StopSignal != "" {
+		args = append(args, "--stop-signal", cfg.StopSignal)
+	}
+
+	// Bring the container back after a crash or a docker daemon restart
+	if cfg.RestartPolicy != "" {
+		args = append(args, "--restart", cfg.RestartPolicy)
+	}
+
+	// Standbys record their primary and may need to reach it through the host
+	args = append(args, replicaArgs(cfg)...)
+
+	// Disposable containers keep their data in memory and disappear when stopped
+	if cfg.AutoRemove {
+		args = append(args, "--rm")
+	}
+	if cfg.Ephemeral {
+		args = append(args, "--tmpfs", dataDir)
+	}
+	if cfg.TrustAuth {
+		args = append(args, "-e", "POSTGRES_HOST_AUTH_METHOD=trust")
+	}
+
+	// Never let docker run fall back to pulling when pulls are skipped
+	if cfg.SkipPull {
+		args = append(args, "--pull", "never")
+	}
+
 	// Add environment variables
 	for k, v := range cfg.Environment {
 		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
@@ -203,6 +698,12 @@ func buildDockerArgs(cfg *Config) []string {
 	if cfg.Memory != "" {
 		args = append(args, "--memory", cfg.Memory)
 	}
+	if cfg.MemorySwap != "" {
+		args = append(args, "--memory-swap", cfg.MemorySwap)
+	}
+	if cfg.ShmSize != "" {
+		args = append(args, "--shm-size", cfg.ShmSize)
+	}
 	if cfg.CPU != "" {
 		args = append(args, "--cpus", cfg.CPU)
 	}
@@ -218,78 +719,310 @@ func buildDockerArgs(cfg *Config) []string {
 	}
 
 	// Handle SSL configuration
-	if cfg.SSLMode != "disable" {
-		if cfg.SSLCert != "" && cfg.SSLKey != "" {
-			args = append(args, "-v", fmt.Sprintf("%s:/var/lib/postgresql/server.crt", cfg.SSLCert))
-			args = append(args, "-v", fmt.Sprintf("%s:/var/lib/postgresql/server.key", cfg.SSLKey))
-			if cfg.SSLRootCert != "" {
-				args = append(args, "-v", fmt.Sprintf("%s:/var/lib/postgresql/root.crt", cfg.SSLRootCert))
-			}
+	if sslEnabled(cfg) {
+		args = append(args, "-v", fmt.Sprintf("%s:/var/lib/postgresql/server.crt", cfg.SSLCert))
+		args = append(args, "-v", fmt.Sprintf("%s:%s:ro", cfg.SSLKey, sslKeySource))
+		args = append(args, "--entrypoint", "sh")
+		if cfg.SSLRootCert != "" {
+			args = append(args, "-v", fmt.Sprintf("%s:/var/lib/postgresql/root.crt", cfg.SSLRootCert))
 		}
 	}
 
-	// Handle initialization scripts
-	if len(cfg.InitScripts) > 0 {
-		for i, script := range cfg.InitScripts {
-			args = append(args, "-v", fmt.Sprintf("%s:/docker-entrypoint-initdb.d/init_%d.sql:ro", script, i))
-		}
+	// Handle initialization scripts; numeric prefixes make the entrypoint run them in order
+	for i, script := range orderInitScripts(cfg.InitScripts, cfg.InitOrder) {
+		name := strings.TrimSuffix(filepath.Base(script), filepath.Ext(script))
+		args = append(args, "-v", fmt.Sprintf("%s:/docker-entrypoint-initdb.d/%03d_%s.sql:ro", script, i, name))
 	}
 
 	// Add image name
-	args = append(args, fmt.Sprintf("postgres:%s", cfg.Version))
+	args = append(args, imageRef(cfg))
+	if sslEnabled(cfg) {
+		args = append(args, "-c", sslEntrypoint, "go-db", "postgres")
+	}
+
+	// Add server settings passed to the postgres command
+	args = append(args, serverArgs(cfg)...)
+
+	return args
+}
 
+// serverArgs builds the "-c name=value" settings passed to the postgres server
+func serverArgs(cfg *Config) []string {
+	var args []string
+	if sslEnabled(cfg) {
+		args = append(args,
+			"-c", "ssl=on",
+			"-c", "ssl_cert_file=/var/lib/postgresql/server.crt",
+			"-c", "ssl_key_file=/var/lib/postgresql/server.key")
+		if cfg.SSLRootCert != "" {
+			args = append(args, "-c", "ssl_ca_file=/var/lib/postgresql/root.crt")
+		}
+	}
+	if cfg.LogQueries {
+		args = append(args, "-c", "log_statement=all", "-c", "log_min_duration_statement=0")
+	}
+	args = append(args, tuningArgs(cfg)...)
+	args = append(args, noFsyncArgs(cfg)...)
+	args = append(args, postgresParamArgs(cfg)...)
 	return args
 }
 
+// validateHealthcheck checks the docker healthcheck and readiness probe durations
+// and the retry count
+func validateHealthcheck(cfg *Config) error {
+	durations := []struct {
+		flag  string
+		value string
+	}{
+		{"--health-interval", cfg.HealthInterval},
+		{"--health-timeout", cfg.HealthTimeout},
+		{"--health-start-period", cfg.HealthStartPeriod},
+		{"--connection-timeout", cfg.ConnectionTimeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(d.value); err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid %s %q, expected a positive duration such as 5s or 1m", d.flag, d.value)
+		}
+	}
+	if cfg.HealthRetries < 0 {
+		return fmt.Errorf("invalid --health-retries %d, must not be negative", cfg.HealthRetries)
+	}
+	return nil
+}
+
+// healthcheckArgs builds the docker run arguments for the container's HEALTHCHECK
+func healthcheckArgs(cfg *Config) []string {
+	healthCmd := fmt.Sprintf("pg_isready -U %s -d %s", cfg.Username, cfg.Database)
+	if cfg.ReadyCommand != "" {
+		healthCmd = cfg.ReadyCommand
+	}
+
+	args := []string{"--health-cmd", healthCmd}
+	if cfg.HealthInterval != "" {
+		args = append(args, "--health-interval", cfg.HealthInterval)
+	}
+	if cfg.HealthTimeout != "" {
+		args = append(args, "--health-timeout", cfg.HealthTimeout)
+	}
+	if cfg.HealthRetries > 0 {
+		args = append(args, "--health-retries", strconv.Itoa(cfg.HealthRetries))
+	}
+	if cfg.HealthStartPeriod != "" {
+		args = append(args, "--health-start-period", cfg.HealthStartPeriod)
+	}
+	return args
+}
+
+// orderInitScripts sequences init scripts: scripts named in order (by file name)
+// come first in that order, the rest follow alphabetically. Without an order
+// the scripts keep the order they were given in.
+func orderInitScripts(scripts, order []string) []string {
+	if len(order) == 0 {
+		return scripts
+	}
+
+	byName := make(map[string]string, len(scripts))
+	for _, script := range scripts {
+		byName[filepath.Base(script)] = script
+	}
+
+	var ordered []string
+	listed := make(map[string]bool, len(order))
+	for _, name := range order {
+		name = filepath.Base(strings.TrimSpace(name))
+		if script, ok := byName[name]; ok && !listed[name] {
+			ordered = append(ordered, script)
+			listed[name] = true
+		}
+	}
+
+	var rest []string
+	for _, script := range scripts {
+		if !listed[filepath.Base(script)] {
+			rest = append(rest, script)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		return filepath.Base(rest[i]) < filepath.Base(rest[j])
+	})
+	return append(ordered, rest...)
+}
+
 func waitForPostgres(cfg *Config) error {
-	maxAttempts := 10 // Reduced from 30
-	for i := 0; i < maxAttempts; i++ {
-		cmd := exec.Command("docker", "exec", cfg.ContainerName, "pg_isready")
-		if err := cmd.Run(); err == nil {
+	if cfg.WaitForHealthy {
+		if checked, err := waitForHealthy(cfg); checked {
+			return err
+		}
+		printf("%s Warning: %s has no healthcheck, falling back to pg_isready\n", warn("⚠"), cfg.ContainerName)
+	}
+
+	probe := databases.Postgres.ReadyCommand
+	if cfg.ReadyCommand != "" {
+		probe = strings.Fields(cfg.ReadyCommand)
+	}
+
+	wait := cfg.ReadinessTimeout
+	if wait <= 0 {
+		wait = defaultReadinessTimeout
+	}
+	timeout := probeTimeout(cfg)
+	err := retryBackoff(wait, maxReadinessInterval, func() error {
+		return probeReady(cfg.ContainerName, probe, timeout)
+	})
+	if err != nil {
+		msg := fmt.Sprintf("PostgreSQL was not ready after %s (--wait-timeout): %v", wait, err)
+		if logs := recentLogs(cfg.ContainerName, 10); logs != "" {
+			msg += "\nLast lines of docker logs " + cfg.ContainerName + ":\n" + logs
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
+// recentLogs returns the last lines a container logged, or "" if they can't be read
+func recentLogs(containerName string, lines int) string {
+	output, err := dockerClient.CombinedOutput("logs", "--tail", strconv.Itoa(lines), containerName)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(output), "\n")
+}
+
+// probeTimeout bounds each readiness probe so an unresponsive server cannot
+// stall a wait
+func probeTimeout(cfg *Config) time.Duration {
+	timeout, err := time.ParseDuration(cfg.ConnectionTimeout)
+	if err != nil || timeout <= 0 {
+		timeout, _ = time.ParseDuration(defaultConnectionTimeout)
+	}
+	return timeout
+}
+
+// probeReady runs a readiness probe such as pg_isready inside the container once
+func probeReady(containerName string, probe []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	output, err := dockerClient.CombinedOutputContext(ctx, append([]string{"exec", containerName}, probe...)...)
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s did not answer within %s", probe[0], timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return fmt.Errorf("%s failed: %v", probe[0], err)
+	}
+	return nil
+}
+
+// retryBackoff calls check until it succeeds or wait has passed, doubling the
+// pause between calls from 100ms up to maxInterval, and returns the last error
+func retryBackoff(wait, maxInterval time.Duration, check func() error) error {
+	deadline := time.Now().Add(wait)
+	interval := 100 * time.Millisecond
+	for {
+		err := check()
+		if err == nil {
 			return nil
 		}
-		time.Sleep(500 * time.Millisecond) // Reduced from 1 second
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return err
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
 	}
-	return fmt.Errorf("timeout waiting for PostgreSQL to be ready")
+}
+
+// retry calls check up to attempts times, pausing interval between calls,
+// until it succeeds, and returns the last error otherwise
+func retry(attempts int, interval time.Duration, check func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		if err = check(); err == nil {
+			return nil
+		}
+	}
+	return err
 }
 
 func Stop(containerName string) error {
 	if exists, running := containerExists(containerName); !exists {
-		return fmt.Errorf("%s Container %s does not exist", errColor("✘"), containerName)
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
 	} else if !running {
-		return fmt.Errorf("%s Container %s is already stopped", warn("⚠"), containerName)
+		return fmt.Errorf("Container %s is already stopped", containerName)
 	}
 
-	fmt.Printf("%s Stopping container %s...\n", info("ℹ"), containerName)
-	cmd := exec.Command("docker", "stop", containerName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("%s Failed to stop container: %v", errColor("✘"), err)
+	printf("%s Stopping container %s...\n", info("ℹ"), containerName)
+	if err := dockerClient.Run("stop", containerName); err != nil {
+		return fmt.Errorf("Failed to stop container: %v", err)
 	}
 
-	fmt.Printf("%s Container %s stopped successfully\n", success("✔"), containerName)
+	printf("%s Container %s stopped successfully\n", success("✔"), containerName)
 	return nil
 }
 
 func Start(containerName string) error {
 	if exists, running := containerExists(containerName); !exists {
-		return fmt.Errorf("%s Container %s does not exist", errColor("✘"), containerName)
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
 	} else if running {
-		return fmt.Errorf("%s Container %s is already running", warn("⚠"), containerName)
+		return fmt.Errorf("Container %s is already running", containerName)
 	}
 
-	fmt.Printf("%s Starting container %s...\n", info("ℹ"), containerName)
-	cmd := exec.Command("docker", "start", containerName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("%s Failed to start container: %v", errColor("✘"), err)
+	printf("%s Starting container %s...\n", info("ℹ"), containerName)
+	if err := dockerClient.Run("start", containerName); err != nil {
+		return fmt.Errorf("Failed to start container: %v", err)
 	}
 
-	fmt.Printf("%s Container %s started successfully\n", success("✔"), containerName)
+	printf("%s Container %s started successfully\n", success("✔"), containerName)
+
+	scripts, err := startupScriptsFromLabel(containerName)
+	if err != nil {
+		return err
+	}
+	if len(scripts) > 0 {
+		cfg, err := inspectConfig(containerName)
+		if err != nil {
+			return fmt.Errorf("Failed to inspect container: %v", err)
+		}
+		cfg.StartupScripts = scripts
+		if err := waitForPostgres(cfg); err != nil {
+			return err
+		}
+		if err := runStartupScripts(cfg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Remove removes a container, its PgBouncer sidecar and what the state file
+// records about it
 func Remove(containerName string, force bool) error {
+	if err := removeContainer(containerName, force); err != nil {
+		return err
+	}
+	if err := utils.ForgetDatabase(containerName); err != nil {
+		printf("%s Warning: Could not update the state file: %v\n", warn("⚠"), err)
+	}
+	return nil
+}
+
+// removeContainer removes a container and its PgBouncer sidecar
+func removeContainer(containerName string, force bool) error {
 	if exists, _ := containerExists(containerName); !exists {
-		return fmt.Errorf("%s Container %s does not exist", errColor("✘"), containerName)
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
 	}
 
 	args := []string{"rm"}
@@ -298,18 +1031,18 @@ func Remove(containerName string, force bool) error {
 	}
 	args = append(args, containerName)
 
-	fmt.Printf("%s Removing container %s...\n", info("ℹ"), containerName)
-	cmd := exec.Command("docker", args...)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("%s Failed to remove container: %v", errColor("✘"), err)
+	printf("%s Removing container %s...\n", info("ℹ"), containerName)
+	if err := dockerClient.Run(args...); err != nil {
+		return fmt.Errorf("Failed to remove container: %v", err)
 	}
 
-	fmt.Printf("%s Container %s removed successfully\n", success("✔"), containerName)
+	printf("%s Container %s removed successfully\n", success("✔"), containerName)
+	removeSidecars(containerName)
 	return nil
 }
 
 func containerExists(name string) (exists bool, running bool) {
-	out, err := exec.Command("docker", "ps", "-a", "--filter", fmt.Sprintf("name=%s", name), "--format", "{{.Status}}").Output()
+	out, err := dockerClient.PS("{{.Status}}", fmt.Sprintf("name=^%s$", regexp.QuoteMeta(name)))
 	if err != nil {
 		return false, false
 	}
@@ -322,75 +1055,345 @@ func containerExists(name string) (exists bool, running bool) {
 	return true, strings.HasPrefix(status, "Up")
 }
 
-func printConnectionDetails(cfg *Config) {
+// ConnectionInfo is the machine-readable form of a container's connection details
+type ConnectionInfo struct {
+	Name             string `json:"name"`
+	Host             string `json:"host"`
+	Port             string `json:"port"`
+	User             string `json:"user"`
+	Password         string `json:"password"`
+	Database         string `json:"database"`
+	ConnectionString string `json:"connectionString"`
+	// AlternateConnectionString uses the host's address of the other IP
+	// family on dual-stack hosts, IPv6 unless --prefer-ipv6 is set
+	AlternateConnectionString string `json:"alternateConnectionString,omitempty"`
+}
+
+// ConnectionDetails returns the connection details of a created container
+func ConnectionDetails(cfg *Config) ConnectionInfo {
+	host, alternate, err := serverHosts(cfg.PreferIPv6)
+	if err != nil {
+		host = "localhost"
+	}
+	details := ConnectionInfo{
+		Name:             cfg.ContainerName,
+		Host:             host,
+		Port:             cfg.Port,
+		User:             cfg.Username,
+		Password:         cfg.Password,
+		Database:         cfg.Database,
+		ConnectionString: connectionURL(cfg, host, cfg.Port),
+	}
+	if alternate != "" {
+		details.AlternateConnectionString = connectionURL(cfg, alternate, cfg.Port)
+	}
+	return details
+}
+
+// serverHosts returns the host's address to show in connection strings and,
+// on a dual-stack host, its address of the other IP family. IPv4 comes first
+// unless preferIPv6 is set; an IPv6-only host always gets its IPv6 address.
+func serverHosts(preferIPv6 bool) (primary, alternate string, err error) {
+	ipv4, err4 := utils.GetOutboundIP()
+	ipv6, err6 := utils.GetOutboundIPv6()
+	switch {
+	case err4 != nil && err6 != nil:
+		return "", "", err4
+	case err6 != nil:
+		return ipv4, "", nil
+	case err4 != nil:
+		return ipv6, "", nil
+	case preferIPv6:
+		return ipv6, ipv4, nil
+	default:
+		return ipv4, ipv6, nil
+	}
+}
+
+// connectionURL builds a postgresql:// URL, bracketing IPv6 hosts as in
+// [2001:db8::1]:5432
+func connectionURL(cfg *Config, host, port string) string {
+	return fmt.Sprintf("postgresql://%s:%s@%s/%s", cfg.Username, cfg.Password, net.JoinHostPort(host, port), cfg.Database)
+}
+
+// printConnectionDetails prints how to reach the server. Replicas are annotated as
+// read-only and get a connection string that only accepts read-only sessions.
+func printConnectionDetails(cfg *Config, role string) {
 	// Get server IP
-	serverIP, err := utils.GetOutboundIP()
+	serverIP, alternateIP, err := serverHosts(cfg.PreferIPv6)
 	if err != nil {
 		serverIP = "localhost" // Fallback to localhost if IP detection fails
-		fmt.Printf("%s Warning: Could not detect server IP, using localhost\n", warn("⚠"))
+		printf("%s Warning: Could not detect server IP, using localhost\n", warn("⚠"))
 	}
 
-	fmt.Printf("\n%s Connection Details:\n", info("ℹ"))
-	fmt.Printf("  %s Host: %s\n", info("→"), serverIP)
-	fmt.Printf("  %s Port: %s\n", info("→"), cfg.Port)
-	fmt.Printf("  %s User: %s\n", info("→"), cfg.Username)
-	fmt.Printf("  %s Password: %s\n", info("→"), cfg.Password)
-	fmt.Printf("  %s Database: %s\n", info("→"), cfg.Database)
+	printf("\n%s Connection Details:\n", info("ℹ"))
+	printf("  %s Host: %s\n", info("→"), serverIP)
+	printf("  %s Port: %s\n", info("→"), cfg.Port)
+	printf("  %s User: %s\n", info("→"), cfg.Username)
+	printf("  %s Password: %s\n", info("→"), cfg.Password)
+	printf("  %s Database: %s\n", info("→"), cfg.Database)
+	if role != "" {
+		printf("  %s Role: %s\n", info("→"), role)
+	}
 	if cfg.Volume != "" {
-		fmt.Printf("  %s Data Volume: %s\n", info("→"), cfg.Volume)
+		printf("  %s Data Volume: %s (%s)\n", info("→"), cfg.Volume, describeVolume(cfg))
 	}
 	if cfg.SSLMode != "disable" {
-		fmt.Printf("  %s SSL Mode: %s\n", info("→"), cfg.SSLMode)
+		printf("  %s SSL Mode: %s\n", info("→"), cfg.SSLMode)
+	}
+	if cfg.MaxWALSize != "" {
+		printf("  %s Max WAL Size: %s\n", info("→"), cfg.MaxWALSize)
+	}
+	if cfg.CheckpointTimeout != "" {
+		printf("  %s Checkpoint Timeout: %s\n", info("→"), cfg.CheckpointTimeout)
+	}
+	for _, param := range sortedParams(cfg.PostgresParams) {
+		printf("  %s Parameter: %s\n", info("→"), param)
+	}
+	for _, label := range sortedParams(cfg.Labels) {
+		printf("  %s Label: %s\n", info("→"), label)
+	}
+
+	printf("\n%s Management Commands:\n", info("ℹ"))
+	printf("  %s Stop:    go-db stop %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Start:   go-db start %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Remove:  go-db remove %s\n", info("→"), cfg.ContainerName)
+	printf("  %s Logs:    go-db logs %s\n", info("→"), cfg.ContainerName)
+	if cfg.LogQueries {
+		printf("  %s Queries: go-db logs %s --follow  (all statements are logged)\n", info("→"), cfg.ContainerName)
 	}
 
-	fmt.Printf("\n%s Management Commands:\n", info("ℹ"))
-	fmt.Printf("  %s Stop:    go-db stop %s\n", info("→"), cfg.ContainerName)
-	fmt.Printf("  %s Start:   go-db start %s\n", info("→"), cfg.ContainerName)
-	fmt.Printf("  %s Remove:  go-db remove %s\n", info("→"), cfg.ContainerName)
-	fmt.Printf("  %s Logs:    docker logs %s\n", info("→"), cfg.ContainerName)
+	printf("\n%s Connection String:\n", info("ℹ"))
+	printf("  %s %s\n", info("→"), connectionURL(cfg, serverIP, cfg.Port))
+
+	if alternateIP != "" {
+		family := "IPv6"
+		if cfg.PreferIPv6 {
+			family = "IPv4"
+		}
+		printf("\n%s %s Connection String:\n", info("ℹ"), family)
+		printf("  %s %s\n", info("→"), connectionURL(cfg, alternateIP, cfg.Port))
+	}
 
-	fmt.Printf("\n%s Connection String:\n", info("ℹ"))
-	fmt.Printf("  %s postgresql://%s:%s@%s:%s/%s\n",
-		info("→"), cfg.Username, cfg.Password, serverIP, cfg.Port, cfg.Database)
+	if role == RoleReplica {
+		printf("\n%s Read-only Connection String (replica, writes will fail):\n", info("ℹ"))
+		printf("  %s %s?target_session_attrs=read-only\n", info("→"), connectionURL(cfg, serverIP, cfg.Port))
+	}
+
+	if cfg.PgBouncerPort != "" {
+		printf("\n%s Pooled Connection String (PgBouncer):\n", info("ℹ"))
+		printf("  %s %s\n", info("→"), connectionURL(cfg, serverIP, cfg.PgBouncerPort))
+	}
 
 	// Try to get public IP for external access
 	publicIP, err := utils.GetPublicIP()
 	if err == nil && publicIP != serverIP {
-		fmt.Printf("\n%s External Connection String:\n", info("ℹ"))
-		fmt.Printf("  %s postgresql://%s:%s@%s:%s/%s\n",
-			info("→"), cfg.Username, cfg.Password, publicIP, cfg.Port, cfg.Database)
+		printf("\n%s External Connection String:\n", info("ℹ"))
+		printf("  %s %s\n", info("→"), connectionURL(cfg, publicIP, cfg.Port))
 	}
 }
 
-// List displays all PostgreSQL containers (both running and stopped)
-func List() error {
-	fmt.Printf("\n%s PostgreSQL Containers\n", info("📦"))
+// ListOptions controls which containers List displays
+type ListOptions struct {
+	Selector string   // label selector in key=value form
+	Prefix   string   // only show containers whose name starts with this prefix
+	Columns  []string // columns to display, in order (default: name, status, port, id)
+	// SinceLastBackup adds the backup column and warns about databases that
+	// are due for a backup
+	SinceLastBackup bool
+	// All lists the containers go-db created of every database type, found by
+	// their managed label, with a type column
+	All bool
+}
+
+// listColumnWidths holds the display width of every column List supports
+var listColumnWidths = map[string]int{
+	"name":   20,
+	"type":   10,
+	"status": 22,
+	"uptime": 12,
+	"port":   8,
+	"id":     12,
+	"backup": 14,
+}
+
+// defaultListColumns are the columns List displays when none are requested
+var defaultListColumns = []string{"name", "status", "port", "id"}
 
-	cmd := exec.Command("docker", "ps", "-a", "--filter", "ancestor=postgres:15", "--format", "{{.Names}}\t{{.Status}}\t{{.Ports}}\t{{.ID}}")
-	output, err := cmd.Output()
+// validateColumns checks that every requested list column is supported
+func validateColumns(columns []string) error {
+	for _, column := range columns {
+		if _, ok := listColumnWidths[column]; !ok {
+			return fmt.Errorf("unknown column %q, expected one of name, type, status, uptime, port, id, backup", column)
+		}
+	}
+	return nil
+}
+
+// containerUptimes returns how long each running container has been up,
+// computed from .State.StartedAt
+func containerUptimes(names []string) map[string]time.Duration {
+	uptimes := make(map[string]time.Duration)
+	output, err := dockerClient.Output(append([]string{"inspect", "--format",
+		"{{.Name}}\t{{.State.Running}}\t{{.State.StartedAt}}"}, names...)...)
 	if err != nil {
-		return fmt.Errorf("%s Failed to list containers: %v", errColor("✘"), err)
+		return uptimes
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[1] != "true" {
+			continue
+		}
+		if started, err := time.Parse(time.RFC3339Nano, fields[2]); err == nil {
+			uptimes[strings.TrimPrefix(fields[0], "/")] = time.Since(started)
+		}
+	}
+	return uptimes
+}
+
+// formatUptime renders a duration with its two most significant units, e.g. 3d 4h
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
+
+// validateSelector checks that a label selector has the form key=value
+func validateSelector(selector string) error {
+	parts := strings.SplitN(selector, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid selector %q, expected format key=value", selector)
+	}
+	return nil
+}
+
+// SelectContainers returns the names of all containers whose labels match the selector
+func SelectContainers(selector string) ([]string, error) {
+	if err := validateSelector(selector); err != nil {
+		return nil, err
 	}
 
-	if len(output) == 0 {
-		// Try again with a more general filter if no containers found
-		cmd = exec.Command("docker", "ps", "-a", "--filter", "ancestor=postgres", "--format", "{{.Names}}\t{{.Status}}\t{{.Ports}}\t{{.ID}}")
-		output, err = cmd.Output()
+	output, err := dockerClient.PS("{{.Names}}", "label="+selector)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list containers: %v", err)
+	}
+
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("No containers match selector %s", selector)
+	}
+	return names, nil
+}
+
+// List displays all PostgreSQL containers (both running and stopped)
+func List(opts ListOptions) error {
+	if err := validateColumns(opts.Columns); err != nil {
+		return err
+	}
+
+	// The type label matches every tag and image. Containers created before
+	// go-db labelled them are only found by image, so those filters are added.
+	kind, matches := "PostgreSQL", []string{
+		databases.Postgres.LabelFilter(),
+		"ancestor=postgres:" + defaultPostgresVersion,
+		"ancestor=postgres",
+	}
+	if opts.All {
+		kind, matches = "go-db", []string{"label=" + databases.ManagedLabel + "=true"}
+	}
+	printf("\n%s %s Containers\n", info("📦"), kind)
+
+	var filters []string
+	if opts.Selector != "" {
+		if err := validateSelector(opts.Selector); err != nil {
+			return err
+		}
+		filters = append(filters, "label="+opts.Selector)
+	}
+	if opts.Prefix != "" {
+		filters = append(filters, "name=^"+regexp.QuoteMeta(opts.Prefix))
+	}
+
+	// docker ps ANDs different filters, so each match is a query of its own
+	var containers []string
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		output, err := dockerClient.PS(fmt.Sprintf("{{.Names}}\t{{.Status}}\t{{.Ports}}\t{{.ID}}\t{{.Label %q}}", databases.TypeLabel),
+			append([]string{match}, filters...)...)
 		if err != nil {
-			return fmt.Errorf("%s Failed to list containers: %v", errColor("✘"), err)
+			return fmt.Errorf("Failed to list containers: %v", err)
+		}
+		for _, container := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			name := strings.Split(container, "\t")[0]
+			if container != "" && !seen[name] {
+				seen[name] = true
+				containers = append(containers, container)
+			}
 		}
 	}
 
-	if len(output) == 0 {
-		fmt.Printf("\n  %s No PostgreSQL containers found\n\n", warn("⚠"))
+	if len(containers) == 0 {
+		printf("\n  %s No %s containers found\n\n", warn("⚠"), kind)
 		return nil
 	}
 
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = defaultListColumns
+		if opts.All {
+			columns = []string{"name", "type", "status", "port", "id"}
+		}
+	}
+	showUptime, showBackup := false, false
+	for _, column := range columns {
+		showUptime = showUptime || column == "uptime"
+		showBackup = showBackup || column == "backup"
+	}
+	if opts.SinceLastBackup && !showBackup {
+		columns = append(append([]string(nil), columns...), "backup")
+		showBackup = true
+	}
+	var backups map[string]time.Time
+	var overdue []string
+	reminder := backupReminder()
+	if showBackup {
+		backups = lastBackups()
+	}
+
+	var uptimes map[string]time.Duration
+	if showUptime {
+		var names []string
+		for _, container := range containers {
+			names = append(names, strings.Split(container, "\t")[0])
+		}
+		uptimes = containerUptimes(names)
+	}
+
 	// Print header with custom formatting
-	fmt.Printf("\n  %-20s %-15s %-15s %s\n", "NAME", "STATUS", "PORT", "CONTAINER ID")
-	fmt.Printf("  %s\n", strings.Repeat("─", 80))
+	var header []string
+	total := 0
+	for _, column := range columns {
+		header = append(header, fmt.Sprintf("%-*s", listColumnWidths[column], strings.ToUpper(column)))
+		total += listColumnWidths[column] + 1
+	}
+	printf("\n  %s\n", strings.Join(header, " "))
+	printf("  %s\n", strings.Repeat("─", total))
 
-	containers := strings.Split(strings.TrimSpace(string(output)), "\n")
 	for _, container := range containers {
 		fields := strings.Split(container, "\t")
 		if len(fields) >= 3 {
@@ -398,9 +1401,13 @@ func List() error {
 			status := fields[1]
 			ports := fields[2]
 			id := ""
-			if len(fields) > 3 {
+			if len(fields) > 3 && len(fields[3]) >= 12 {
 				id = fields[3][:12] // Show first 12 chars of container ID
 			}
+			dbType := "-"
+			if len(fields) > 4 && fields[4] != "" {
+				dbType = fields[4]
+			}
 
 			// Extract just the host port for cleaner display
 			port := "N/A"
@@ -416,68 +1423,219 @@ func List() error {
 				statusSymbol = "🟢" // Green circle for running
 			}
 
-			// Format the status to be more concise
+			// Format the status to be more concise; the uptime gets its own column when shown
 			shortStatus := "Stopped ⏹️"
 			if strings.HasPrefix(status, "Up") {
-				upTime := strings.TrimPrefix(status, "Up ")
-				shortStatus = "Running ⏵️ " + upTime
+				shortStatus = "Running ⏵️"
+				if !showUptime {
+					shortStatus += " " + strings.TrimPrefix(status, "Up ")
+				}
+			}
+
+			uptime := "-"
+			if d, ok := uptimes[name]; ok {
+				uptime = formatUptime(d)
+			}
+
+			lastBackup := "-"
+			if showBackup && persistentData(name) {
+				lastBackup = formatLastBackup(backups[name])
+				if opts.SinceLastBackup && backupOverdue(backups[name], reminder) {
+					overdue = append(overdue, name)
+				}
 			}
 
-			fmt.Printf("  %-20s %s  %-25s%s %-15s %s\n",
-				info(name),
-				statusSymbol,
-				statusColor(shortStatus),
-				utils.ResetColor(),
-				port,
-				id)
+			var cells []string
+			for _, column := range columns {
+				width := listColumnWidths[column]
+				switch column {
+				case "name":
+					cells = append(cells, info(fmt.Sprintf("%-*s", width, name)))
+				case "type":
+					cells = append(cells, fmt.Sprintf("%-*s", width, dbType))
+				case "status":
+					cells = append(cells, fmt.Sprintf("%s %s", statusSymbol, statusColor(fmt.Sprintf("%-*s", width-3, shortStatus))))
+				case "uptime":
+					cells = append(cells, fmt.Sprintf("%-*s", width, uptime))
+				case "port":
+					cells = append(cells, fmt.Sprintf("%-*s", width, port))
+				case "id":
+					cells = append(cells, fmt.Sprintf("%-*s", width, id))
+				case "backup":
+					cells = append(cells, fmt.Sprintf("%-*s", width, lastBackup))
+				}
+			}
+			printf("  %s\n", strings.Join(cells, " "))
 		}
 	}
-	fmt.Println()
+	printf("\n")
+	for _, name := range overdue {
+		printf("  %s %s\n", warn("⚠"), backupWarning(name, backups[name], reminder))
+	}
+	if len(overdue) > 0 {
+		printf("\n")
+	}
 	return nil
 }
 
+// ShowOptions controls how ShowConnectionDetails presents a container
+type ShowOptions struct {
+	Redact     bool // replace the password with <redacted> everywhere it appears
+	JSON       bool // print the details as a JSON object instead of text
+	PreferIPv6 bool // use the host's IPv6 address in the main connection string
+}
+
+// ShowInfo is the machine-readable form of show's output. ConnectionString
+// uses the host's local address; ExternalConnectionString its public one, if
+// it has a different one.
+type ShowInfo struct {
+	ConnectionInfo
+	SSLMode                  string            `json:"sslMode"`
+	ExternalConnectionString string            `json:"externalConnectionString,omitempty"`
+	LastBackup               *time.Time        `json:"lastBackup,omitempty"` // only for databases with a data volume that were backed up
+	Labels                   map[string]string `json:"labels,omitempty"`     // labels set with --label
+}
+
+// redactedPassword replaces the password in redacted output
+const redactedPassword = "<redacted>"
+
+// PrintConfig writes the configuration as indented JSON with the password
+// redacted, showing how flags, environment variables and config files combined
+func PrintConfig(w io.Writer, cfg *Config) error {
+	resolved := *cfg
+	resolved.ContainerName = WithPrefix(cfg.Prefix, cfg.ContainerName)
+	if resolved.Password != "" {
+		resolved.Password = redactedPassword
+	}
+	if resolved.ReplicationPassword != "" {
+		resolved.ReplicationPassword = redactedPassword
+	}
+	if resolved.RegistryAuth != "" {
+		resolved.RegistryAuth = redactedPassword
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(resolved)
+}
+
 // ShowConnectionDetails displays connection information for a specific container
-func ShowConnectionDetails(containerName string) error {
-	if exists, _ := containerExists(containerName); !exists {
-		return fmt.Errorf("%s Container %s does not exist", errColor("✘"), containerName)
+func ShowConnectionDetails(containerName string, opts ShowOptions) error {
+	exists, running := containerExists(containerName)
+	if !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", containerName)
 	}
 
-	// Get container details using docker inspect
-	cmd := exec.Command("docker", "inspect",
-		"--format",
-		"{{range $k, $v := .Config.Env}}{{$v}}{{println}}{{end}}",
-		containerName)
-	output, err := cmd.Output()
+	cfg, err := inspectConfig(containerName)
 	if err != nil {
-		return fmt.Errorf("%s Failed to get container details: %v", errColor("✘"), err)
+		return fmt.Errorf("Failed to get container details: %v", err)
+	}
+	cfg.PgBouncerPort = pgbouncerPort(containerName)
+	if cfg.Labels, err = userLabels(containerName); err != nil {
+		return err
+	}
+
+	// The role can only be asked of a running server
+	role := ""
+	if running {
+		role = serverRole(cfg)
+	}
+
+	if opts.Redact {
+		cfg.Password = redactedPassword
+	}
+	cfg.PreferIPv6 = opts.PreferIPv6
+
+	persistent := persistentData(containerName)
+	lastBackup := lastBackups()[containerName]
+
+	if opts.JSON {
+		details := ShowInfo{ConnectionInfo: ConnectionDetails(cfg), SSLMode: cfg.SSLMode, Labels: cfg.Labels}
+		if persistent && !lastBackup.IsZero() {
+			details.LastBackup = &lastBackup
+		}
+		if publicIP, err := utils.GetPublicIP(); err == nil && publicIP != details.Host {
+			details.ExternalConnectionString = connectionURL(cfg, publicIP, cfg.Port)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(details)
+	}
+
+	printConnectionDetails(cfg, role)
+
+	// Tmpfs-backed databases lose their data on stop anyway
+	if persistent {
+		printf("\n%s Last Backup: %s\n", info("ℹ"), formatLastBackup(lastBackup))
+		if reminder := backupReminder(); backupOverdue(lastBackup, reminder) {
+			printf("  %s %s\n", warn("⚠"), backupWarning(containerName, lastBackup, reminder))
+		}
+	}
+	return nil
+}
+
+// containerEnv returns the environment variables a container was created with
+func containerEnv(containerName string) (map[string]string, error) {
+	output, err := dockerClient.Inspect(containerName, "{{range $k, $v := .Config.Env}}{{$v}}{{println}}{{end}}")
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse environment variables
 	env := make(map[string]string)
 	for _, line := range strings.Split(string(output), "\n") {
 		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
 			env[parts[0]] = parts[1]
 		}
 	}
+	return env, nil
+}
 
-	// Get port mapping
-	cmd = exec.Command("docker", "inspect",
-		"--format",
-		"{{range $p, $conf := .NetworkSettings.Ports}}{{if eq $p \"5432/tcp\"}}{{range $conf}}{{.HostPort}}{{end}}{{end}}{{end}}",
-		containerName)
-	portBytes, err := cmd.Output()
+// containerPort returns the host port bound to the container's PostgreSQL port
+func containerPort(containerName string) (string, error) {
+	output, err := dockerClient.Inspect(containerName,
+		"{{range $p, $conf := .HostConfig.PortBindings}}{{if eq $p \"5432/tcp\"}}{{range $conf}}{{.HostPort}}{{end}}{{end}}{{end}}")
 	if err != nil {
-		return fmt.Errorf("%s Failed to get port mapping: %v", errColor("✘"), err)
+		return "", fmt.Errorf("failed to get port mapping: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// containerImage returns the image reference a container was created from
+func containerImage(containerName string) (string, error) {
+	output, err := dockerClient.Inspect(containerName, "{{.Config.Image}}")
+	if err != nil {
+		return "", fmt.Errorf("failed to get container image: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// inspectConfig reconstructs the connection-relevant parts of a Config from an existing container
+func inspectConfig(containerName string) (*Config, error) {
+	env, err := containerEnv(containerName)
+	if err != nil {
+		return nil, err
+	}
+	port, err := containerPort(containerName)
+	if err != nil {
+		return nil, err
 	}
-	port := strings.TrimSpace(string(portBytes))
 
-	// Create a temporary config to reuse the existing printConnectionDetails function
 	cfg := &Config{
 		ContainerName: containerName,
 		Port:          port,
-		Username:      strings.TrimPrefix(env["POSTGRES_USER"], "POSTGRES_USER="),
-		Password:      strings.TrimPrefix(env["POSTGRES_PASSWORD"], "POSTGRES_PASSWORD="),
-		Database:      strings.TrimPrefix(env["POSTGRES_DB"], "POSTGRES_DB="),
+		Username:      env["POSTGRES_USER"],
+		Password:      env["POSTGRES_PASSWORD"],
+		Database:      env["POSTGRES_DB"],
+		Timezone:      env["TZ"],
+		Locale:        env["LANG"],
+	}
+
+	if image, err := containerImage(containerName); err == nil {
+		if i := strings.LastIndex(image, ":"); i != -1 {
+			cfg.Version = image[i+1:]
+		}
 	}
 
 	if cfg.Username == "" {
@@ -486,7 +1644,36 @@ func ShowConnectionDetails(containerName string) error {
 	if cfg.Database == "" {
 		cfg.Database = cfg.Username // default database if not set
 	}
+	return cfg, nil
+}
+
+// reuseContainer treats an already existing container as the result of a create,
+// starting it if it is stopped and warning when its configuration has drifted
+func reuseContainer(cfg *Config, running bool) error {
+	printf("%s Container %s already exists, reusing it\n", info("ℹ"), cfg.ContainerName)
+
+	existing, err := inspectConfig(cfg.ContainerName)
+	if err != nil {
+		return fmt.Errorf("Failed to inspect existing container: %v", err)
+	}
+
+	if cfg.Version != "" && existing.Version != cfg.Version {
+		printf("%s Warning: Existing container runs version %s, requested %s\n", warn("⚠"), existing.Version, cfg.Version)
+	}
+	if cfg.Port != defaultPort && existing.Port != cfg.Port {
+		printf("%s Warning: Existing container uses port %s, requested %s\n", warn("⚠"), existing.Port, cfg.Port)
+	}
+
+	if !running {
+		if err := Start(cfg.ContainerName); err != nil {
+			return err
+		}
+		existing.ReadyCommand = cfg.ReadyCommand
+		if err := waitForPostgres(existing); err != nil {
+			return err
+		}
+	}
 
-	printConnectionDetails(cfg)
+	printConnectionDetails(existing, serverRole(existing))
 	return nil
 }
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// psqlQuery runs a SQL statement inside the container with psql and returns
+// its unaligned, tuples-only output
+func psqlQuery(cfg *Config, sql string) (string, error) {
+	output, err := dockerClient.CombinedOutput("exec", cfg.ContainerName,
+		"psql", "-U", cfg.Username, "-d", cfg.Database, "-v", "ON_ERROR_STOP=1", "-tAc", sql)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// quoteIdentifier quotes a SQL identifier such as a database name
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
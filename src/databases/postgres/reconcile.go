@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// containerResources returns the memory limit in bytes and the CPU limit in
+// nano CPUs of a container; zero means unlimited
+func containerResources(containerName string) (memory, nanoCPUs int64, err error) {
+	output, err := dockerClient.Output("inspect", "--format",
+		"{{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}", containerName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get container resources: %v", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected resource output %q", strings.TrimSpace(string(output)))
+	}
+	memory, _ = strconv.ParseInt(fields[0], 10, 64)
+	nanoCPUs, _ = strconv.ParseInt(fields[1], 10, 64)
+	return memory, nanoCPUs, nil
+}
+
+// parseMemory converts a docker memory limit such as 512m or 1g to bytes
+func parseMemory(limit string) (int64, error) {
+	if limit == "" {
+		return 0, nil
+	}
+	units := map[byte]int64{'b': 1, 'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30}
+	s := strings.ToLower(limit)
+	multiplier := int64(1)
+	if unit, ok := units[s[len(s)-1]]; ok {
+		multiplier = unit
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit %q", limit)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// parseCPUs converts a docker CPU limit such as 0.5 to nano CPUs
+func parseCPUs(limit string) (int64, error) {
+	if limit == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(limit, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU limit %q", limit)
+	}
+	return int64(n * 1e9), nil
+}
+
+// compareConfig inspects an existing container and describes every way its
+// configuration differs from the desired one. Differences recreating the
+// container would not fix, such as a new password for data that already
+// exists, are returned as notes instead of drift.
+func compareConfig(existing string, desired *Config) (drift, notes []string, err error) {
+	current, err := inspectConfig(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := containerEnv(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	differs := func(field, have, want string) {
+		if have != want {
+			drift = append(drift, fmt.Sprintf("%s: %q → %q", field, have, want))
+		}
+	}
+
+	differs("version", current.Version, desired.Version)
+	if desired.Port != defaultPort {
+		differs("port", current.Port, desired.Port)
+	}
+	differs("user", current.Username, desired.Username)
+	differs("database", current.Database, desired.Database)
+	// The image only sets the password when it initializes an empty data
+	// directory, and a generated password is never a request to change it
+	if !desired.PasswordGenerated && current.Password != desired.Password {
+		notes = append(notes, "password: changed, but not applied to an existing volume; change it with ALTER ROLE")
+	}
+	differs("timezone", current.Timezone, desired.Timezone)
+	differs("locale", current.Locale, desired.Locale)
+	for k, v := range desired.Environment {
+		differs("env "+k, env[k], v)
+	}
+
+	memory, nanoCPUs, err := containerResources(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+	wantMemory, err := parseMemory(desired.Memory)
+	if err != nil {
+		return nil, nil, err
+	}
+	wantCPUs, err := parseCPUs(desired.CPU)
+	if err != nil {
+		return nil, nil, err
+	}
+	if memory != wantMemory {
+		drift = append(drift, fmt.Sprintf("memory: %d → %d bytes", memory, wantMemory))
+	}
+	if nanoCPUs != wantCPUs {
+		drift = append(drift, fmt.Sprintf("cpu: %g → %g", float64(nanoCPUs)/1e9, float64(wantCPUs)/1e9))
+	}
+	return drift, notes, nil
+}
+
+// reconcile compares an existing container with the desired configuration.
+// Without drift the container is reused as is. With drift it is removed so it
+// can be recreated when ForceRecreate is set, and an error is returned otherwise.
+func reconcile(cfg *Config, running bool) (recreate bool, err error) {
+	drift, notes, err := compareConfig(cfg.ContainerName, cfg)
+	if err != nil {
+		return false, fmt.Errorf("Failed to inspect existing container: %v", err)
+	}
+	for _, note := range notes {
+		printf("%s Warning: %s\n", warn("⚠"), note)
+	}
+
+	if len(drift) == 0 {
+		printf("%s Container %s matches the requested configuration\n", success("✔"), cfg.ContainerName)
+		return false, reuseContainer(cfg, running)
+	}
+
+	printf("%s Container %s has drifted from the requested configuration:\n", warn("⚠"), cfg.ContainerName)
+	for _, d := range drift {
+		printf("  %s %s\n", info("→"), d)
+	}
+
+	if !cfg.ForceRecreate {
+		// Offer to recreate when someone is at the terminal; never block automation
+		if ok, err := utils.Confirm(fmt.Sprintf("Recreate %s with the requested configuration?", cfg.ContainerName)); err == nil && ok {
+			cfg.ForceRecreate = true
+		}
+	}
+	if !cfg.ForceRecreate {
+		return false, fmt.Errorf("Container %s is out of date; rerun with --force-recreate-on-config-change to recreate it",
+			cfg.ContainerName)
+	}
+
+	if cfg.Volume == "" {
+		printf("%s Warning: No --volume is set, so the data in %s is lost when it is recreated\n", warn("⚠"), cfg.ContainerName)
+	}
+	// The recreated container keeps the name and data, so its recorded
+	// backups still apply
+	if err := removeContainer(cfg.ContainerName, true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases"
+)
+
+// defaultImage is the image repository used unless --image names another one
+var defaultImage = databases.Postgres.Image
+
+// imageRef returns the image reference containers are created from
+func imageRef(cfg *Config) string {
+	image := cfg.Image
+	if image == "" {
+		image = defaultImage
+	}
+	return fmt.Sprintf("%s:%s", image, cfg.Version)
+}
+
+// registryHost returns the registry an image is pulled from; references
+// without a registry host come from Docker Hub
+func registryHost(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first
+	}
+	return "docker.io"
+}
+
+// validateImage checks that --image names a repository only: the tag always
+// comes from --version, so a tag or digest would end up doubled
+func validateImage(image string) error {
+	if image == "" {
+		return nil
+	}
+	last := image[strings.LastIndex(image, "/")+1:]
+	if strings.Contains(last, ":") || strings.Contains(image, "@") {
+		return fmt.Errorf("invalid --image %q: give the repository without a tag or digest, the tag comes from --version", image)
+	}
+	return nil
+}
+
+// registryUserEnv and registryPasswordEnv carry registry credentials as two
+// variables, the way CI systems usually hand them out
+const (
+	registryUserEnv     = "GODB_REGISTRY_USER"
+	registryPasswordEnv = "GODB_REGISTRY_PASSWORD"
+)
+
+// registryAuth returns the user:password to log in with: --registry-auth (or
+// GODB_REGISTRY_AUTH), else GODB_REGISTRY_USER and GODB_REGISTRY_PASSWORD.
+// Empty means the credentials docker already holds are used.
+func registryAuth(cfg *Config) (string, error) {
+	if cfg.RegistryAuth != "" {
+		return cfg.RegistryAuth, nil
+	}
+	user, password := os.Getenv(registryUserEnv), os.Getenv(registryPasswordEnv)
+	if user == "" && password == "" {
+		return "", nil
+	}
+	if user == "" || password == "" {
+		return "", fmt.Errorf("%s and %s must be set together", registryUserEnv, registryPasswordEnv)
+	}
+	return user + ":" + password, nil
+}
+
+// validateRegistryAuth checks the user:password form of --registry-auth
+// without ever echoing the value
+func validateRegistryAuth(auth string) error {
+	if auth == "" {
+		return nil
+	}
+	if user, password, ok := strings.Cut(auth, ":"); !ok || user == "" || password == "" {
+		return fmt.Errorf("invalid --registry-auth, expected user:password")
+	}
+	return nil
+}
+
+// registryLogin logs in to a registry. The password is passed on stdin, so it
+// shows up in neither the process list nor any output.
+func registryLogin(registry, auth string) error {
+	user, password, _ := strings.Cut(auth, ":")
+	var output bytes.Buffer
+	if err := dockerClient.Stream(strings.NewReader(password), &output, &output, "login", registry, "--username", user, "--password-stdin"); err != nil {
+		return fmt.Errorf("login to %s failed: %s", registry, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// dockerLoggedIn reports whether the docker CLI holds credentials for a
+// registry, either in ~/.docker/config.json or through a credential helper
+func dockerLoggedIn(registry string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".docker", "config.json"))
+	if err != nil {
+		return false
+	}
+
+	var config struct {
+		Auths       map[string]json.RawMessage `json:"auths"`
+		CredHelpers map[string]string          `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return false
+	}
+
+	keys := []string{registry, "https://" + registry}
+	if registry == "docker.io" {
+		keys = append(keys, "https://index.docker.io/v1/", "index.docker.io")
+	}
+	for _, key := range keys {
+		if _, ok := config.Auths[key]; ok {
+			return true
+		}
+		if _, ok := config.CredHelpers[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pullImage pulls the image unless it is present, logging in first when
+// credentials are given. A refused pull from a registry without stored
+// credentials is reported as an authentication problem.
+func pullImage(cfg *Config) error {
+	image := imageRef(cfg)
+	if out, _ := dockerClient.Output("images", "-q", image); len(out) > 0 {
+		return nil
+	}
+
+	auth, err := registryAuth(cfg)
+	if err != nil {
+		return err
+	}
+	registry := registryHost(image)
+	if auth != "" {
+		if err := registryLogin(registry, auth); err != nil {
+			return err
+		}
+	}
+
+	output, err := dockerClient.CombinedOutput("pull", image)
+	if err == nil {
+		return nil
+	}
+	message := strings.TrimSpace(string(output))
+	lower := strings.ToLower(message)
+	denied := strings.Contains(lower, "unauthorized") || strings.Contains(lower, "denied") ||
+		strings.Contains(lower, "authentication required")
+	if denied && auth == "" && !dockerLoggedIn(registry) {
+		return fmt.Errorf("not authenticated to %s; run docker login %s, pass --registry-auth user:password or set %s and %s",
+			registry, registry, registryUserEnv, registryPasswordEnv)
+	}
+	return fmt.Errorf("failed to pull %s: %s", image, message)
+}
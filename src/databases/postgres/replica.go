@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// replicaOfLabel records the external primary a standby container follows
+const replicaOfLabel = "go-db.replica-of"
+
+// hostGateway is the name containers use to reach the docker host
+const hostGateway = "host.docker.internal"
+
+// parseReplicaOf splits a --replica-of address into host and port (default 5432)
+func parseReplicaOf(replicaOf string) (host, port string, err error) {
+	host, port, err = net.SplitHostPort(replicaOf)
+	if err != nil {
+		// No port given
+		host, port = strings.Trim(replicaOf, "[]"), "5432"
+	}
+	if host == "" {
+		return "", "", fmt.Errorf("invalid --replica-of %q, expected host:port", replicaOf)
+	}
+	return host, port, nil
+}
+
+// primaryHost returns the address the primary is reachable at from inside a
+// container. A primary on the host's loopback interface is reached through the
+// host gateway, which the containers then need an --add-host entry for.
+func primaryHost(host string) (string, bool) {
+	if host == "localhost" {
+		return hostGateway, true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return hostGateway, true
+	}
+	return host, false
+}
+
+// validateReplica checks the standby settings
+func validateReplica(cfg *Config) error {
+	if cfg.ReplicaOf == "" {
+		if cfg.ReplicationUser != "" || cfg.ReplicationPassword != "" {
+			return fmt.Errorf("--replication-user and --replication-password require --replica-of")
+		}
+		return nil
+	}
+	if _, _, err := parseReplicaOf(cfg.ReplicaOf); err != nil {
+		return err
+	}
+	if cfg.ReplicationUser == "" {
+		return fmt.Errorf("--replica-of requires --replication-user")
+	}
+	if cfg.CopyFrom != "" {
+		return fmt.Errorf("--replica-of and --copy-from are mutually exclusive")
+	}
+	if cfg.Ephemeral {
+		return fmt.Errorf("--replica-of needs a persistent data directory and cannot be combined with --test")
+	}
+	return nil
+}
+
+// prepareReplica picks the data volume the base backup is written to. Like a
+// copy, the standby's data comes from the primary, so its roles and passwords
+// are the primary's.
+func prepareReplica(cfg *Config) error {
+	if cfg.Volume == "" {
+		cfg.Volume = cfg.ContainerName + "-data"
+	}
+	if isNamedVolume(cfg.Volume) {
+		if err := dockerClient.Run("volume", "inspect", cfg.Volume); err == nil {
+			return fmt.Errorf("Volume %s already exists; choose another --volume for the replica", cfg.Volume)
+		}
+	}
+	printf("%s The standby uses the roles and passwords of %s; pass its credentials with --user and --password\n",
+		info("ℹ"), cfg.ReplicaOf)
+	return nil
+}
+
+// baseBackup clones the external primary into the data volume with
+// pg_basebackup. -R writes standby.signal and primary_conninfo, so the
+// container starts as a streaming standby.
+func baseBackup(cfg *Config) error {
+	host, port, err := parseReplicaOf(cfg.ReplicaOf)
+	if err != nil {
+		return err
+	}
+	host, viaGateway := primaryHost(host)
+
+	if isNamedVolume(cfg.Volume) {
+		if err := createManagedVolume(cfg.Volume, cfg.VolumeDriver); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"run", "--rm",
+		"-v", fmt.Sprintf("%s:%s", cfg.Volume, dataDir),
+		"-e", "PGPASSWORD=" + cfg.ReplicationPassword,
+	}
+	if viaGateway {
+		args = append(args, "--add-host", hostGateway+":host-gateway")
+	}
+	if cfg.SkipPull {
+		args = append(args, "--pull", "never")
+	}
+	args = append(args, imageRef(cfg),
+		"pg_basebackup", "-h", host, "-p", port, "-U", cfg.ReplicationUser,
+		"-D", dataDir, "-X", "stream", "-R", "--checkpoint", "fast")
+
+	if output, err := dockerClient.CombinedOutput(args...); err != nil {
+		return fmt.Errorf("pg_basebackup from %s failed: %v: %s", cfg.ReplicaOf, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// replicaArgs builds the docker run arguments of a standby container
+func replicaArgs(cfg *Config) []string {
+	if cfg.ReplicaOf == "" {
+		return nil
+	}
+
+	args := []string{"--label", fmt.Sprintf("%s=%s", replicaOfLabel, cfg.ReplicaOf)}
+	if host, _, err := parseReplicaOf(cfg.ReplicaOf); err == nil {
+		if _, viaGateway := primaryHost(host); viaGateway {
+			args = append(args, "--add-host", hostGateway+":host-gateway")
+		}
+	}
+	return args
+}
@@ -0,0 +1,20 @@
+package postgres
+
+// Server roles reported by pg_is_in_recovery
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+)
+
+// serverRole asks a running server whether it is a primary or a replica in
+// recovery. It returns "" when the role cannot be determined.
+func serverRole(cfg *Config) string {
+	output, err := psqlQuery(cfg, "SELECT pg_is_in_recovery()")
+	if err != nil {
+		return ""
+	}
+	if output == "t" {
+		return RoleReplica
+	}
+	return RolePrimary
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases"
+	dberrors "github.com/awade12/go-db/src/errors"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// Suffixes of the containers swapped by a rollback. An upgrade keeps the old
+// container as <name>.bak; a rollback keeps the replaced one as <name>.rolled-back.
+const (
+	backupSuffix     = ".bak"
+	rolledBackSuffix = ".rolled-back"
+)
+
+// Rollback reverses an upgrade: it stops the upgraded container, moves it
+// aside as <name>.rolled-back and brings <name>.bak back under its original
+// name. The upgraded container is kept so its data can still be inspected.
+func Rollback(containerName string) error {
+	backup := containerName + backupSuffix
+	if exists, _ := containerExists(backup); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "No backup container %s found; rollback restores the container an upgrade keeps as <name>%s", backup, backupSuffix)
+	}
+
+	if exists, running := containerExists(containerName); exists {
+		aside := containerName + rolledBackSuffix
+		if taken, _ := containerExists(aside); taken {
+			return dberrors.Newf(dberrors.ErrContainerExists, "Container %s already exists; remove it before rolling back again", aside)
+		}
+		if running {
+			if err := Stop(containerName); err != nil {
+				return err
+			}
+		}
+		if err := renameContainer(containerName, aside); err != nil {
+			return err
+		}
+	}
+
+	if err := renameContainer(backup, containerName); err != nil {
+		return err
+	}
+	if _, running := containerExists(containerName); running {
+		printf("%s Container %s restored from %s\n", success("✔"), containerName, backup)
+		return nil
+	}
+	if err := Start(containerName); err != nil {
+		return err
+	}
+
+	cfg, err := inspectConfig(containerName)
+	if err != nil {
+		return fmt.Errorf("Failed to inspect container: %v", err)
+	}
+	if err := waitForPostgres(cfg); err != nil {
+		return err
+	}
+	printf("%s Container %s restored from %s\n", success("✔"), containerName, backup)
+	return nil
+}
+
+// Rename gives a container a new name. Only the container and its PgBouncer
+// sidecar are renamed: the database inside keeps the name it was created
+// with (POSTGRES_DB).
+func Rename(oldName, newName string) error {
+	if oldName == newName {
+		return fmt.Errorf("Container %s already has that name", oldName)
+	}
+	if err := databases.ValidateContainerName(newName); err != nil {
+		return err
+	}
+	if exists, _ := containerExists(oldName); !exists {
+		return dberrors.Newf(dberrors.ErrContainerNotFound, "Container %s does not exist", oldName)
+	}
+	if exists, _ := containerExists(newName); exists {
+		return dberrors.Newf(dberrors.ErrContainerExists, "Container %s already exists", newName)
+	}
+
+	if err := renameContainer(oldName, newName); err != nil {
+		return err
+	}
+	if err := renameSidecars(oldName, newName); err != nil {
+		return fmt.Errorf("Container %s was renamed to %s, but moving its PgBouncer sidecar failed: %v", oldName, newName, err)
+	}
+
+	// Keep the recorded last backup with the container
+	if err := utils.UpdateState(func(state *utils.State) {
+		if db, ok := state.Databases[oldName]; ok {
+			state.Databases[newName] = db
+			delete(state.Databases, oldName)
+		}
+	}); err != nil {
+		printf("%s Warning: Could not update the state file: %v\n", warn("⚠"), err)
+	}
+
+	printf("%s Container %s renamed to %s\n", success("✔"), oldName, newName)
+	printf("%s Only the container name changed; the database inside (POSTGRES_DB) keeps its name, so connection strings still use it\n", info("ℹ"))
+	return nil
+}
+
+// renameContainer gives a container a new name
+func renameContainer(from, to string) error {
+	if output, err := dockerClient.CombinedOutput("rename", from, to); err != nil {
+		return fmt.Errorf("Failed to rename %s to %s: %v: %s", from, to, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// sslEnabled reports whether the server should be started with SSL
+func sslEnabled(cfg *Config) bool {
+	return cfg.SSLMode != "disable" && cfg.SSLCert != "" && cfg.SSLKey != ""
+}
+
+// sslModes are the accepted --ssl-mode values
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// validateSSL checks the SSL mode and that the certificate files it needs
+// exist. With --ssl-gen the files are generated later, so only the mode is
+// checked.
+func validateSSL(cfg *Config) error {
+	valid := cfg.SSLMode == ""
+	for _, mode := range sslModes {
+		valid = valid || cfg.SSLMode == mode
+	}
+	if !valid {
+		return fmt.Errorf("invalid --ssl-mode %q, expected disable, require, verify-ca or verify-full", cfg.SSLMode)
+	}
+	if cfg.SSLGen {
+		return nil
+	}
+
+	if (cfg.SSLMode == "verify-ca" || cfg.SSLMode == "verify-full") && (cfg.SSLCert == "" || cfg.SSLKey == "") {
+		return fmt.Errorf("--ssl-mode %s requires the server certificate (--ssl-cert) and its private key (--ssl-key), or --ssl-gen to generate them", cfg.SSLMode)
+	}
+	if (cfg.SSLCert == "") != (cfg.SSLKey == "") {
+		return fmt.Errorf("--ssl-cert and --ssl-key must be given together")
+	}
+
+	files := []struct {
+		flag string
+		path string
+	}{
+		{"--ssl-cert", cfg.SSLCert},
+		{"--ssl-key", cfg.SSLKey},
+		{"--ssl-root-cert", cfg.SSLRootCert},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		fi, err := os.Stat(file.path)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %s does not exist", file.flag, file.path)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.flag, err)
+		}
+		if fi.IsDir() {
+			return fmt.Errorf("%s %s is a directory, expected a PEM file", file.flag, file.path)
+		}
+	}
+	return nil
+}
+
+// generateCertificate creates a self-signed certificate for the container in
+// <output-dir>/certs/<name>/, or ~/.go-db/certs/<name>/ when no output
+// directory is configured, and points the SSL configuration at it
+func generateCertificate(cfg *Config) error {
+	dir, err := certDir(cfg)
+	if err != nil {
+		return err
+	}
+
+	certPath, keyPath, err := utils.GenerateSelfSignedCert(dir, cfg.ContainerName, utils.CertHosts())
+	if err != nil {
+		return err
+	}
+
+	cfg.SSLCert = certPath
+	cfg.SSLKey = keyPath
+	if cfg.SSLMode == "" || cfg.SSLMode == "disable" {
+		cfg.SSLMode = "require"
+	}
+	printf("%s Generated self-signed certificate in %s\n", info("ℹ"), dir)
+	return nil
+}
+
+// certDir returns the directory generated certificates are written to
+func certDir(cfg *Config) (string, error) {
+	outputDir, err := utils.ConfiguredOutputDir(cfg.OutputDir)
+	if err != nil {
+		return "", err
+	}
+	if outputDir == "" {
+		return utils.ConfigDir("certs", cfg.ContainerName)
+	}
+
+	dir, err := filepath.Abs(filepath.Join(outputDir, "certs", cfg.ContainerName))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	return dir, nil
+}
+
+// sslKeySource is where the host key is mounted read-only. PostgreSQL
+// refuses keys it does not own, and the postgres uid differs between images
+// (999 on Debian, 70 on Alpine), so sslEntrypoint copies the key into place
+// as the container's postgres user before handing over to the image's
+// entrypoint.
+const sslKeySource = "/etc/go-db/ssl/server.key"
+
+const sslEntrypoint = `cp ` + sslKeySource + ` /var/lib/postgresql/server.key && ` +
+	`chown postgres:postgres /var/lib/postgresql/server.key && ` +
+	`chmod 600 /var/lib/postgresql/server.key && ` +
+	`exec docker-entrypoint.sh "$@"`
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// startupScriptsLabel records the startup scripts on the container so that
+// Start can run them again
+const startupScriptsLabel = "go-db.startup-scripts"
+
+// containerLabel returns the value of a label on a container, or "" if it is not set
+func containerLabel(containerName, key string) (string, error) {
+	output, err := dockerClient.Output("inspect",
+		"--format", fmt.Sprintf("{{index .Config.Labels %q}}", key),
+		containerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container labels: %v", err)
+	}
+	value := strings.TrimSpace(string(output))
+	if value == "<no value>" {
+		return "", nil
+	}
+	return value, nil
+}
+
+// resolveStartupScripts makes the startup script paths absolute so the label
+// stays valid when the container is started from another directory
+func resolveStartupScripts(cfg *Config) error {
+	for i, script := range cfg.StartupScripts {
+		path, err := filepath.Abs(script)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("startup script %s: %v", script, err)
+		}
+		cfg.StartupScripts[i] = path
+	}
+	return nil
+}
+
+// runStartupScripts executes each startup script with psql inside the
+// container, reporting the outcome of every script
+func runStartupScripts(cfg *Config) error {
+	failed := 0
+	for _, script := range cfg.StartupScripts {
+		if err := runStartupScript(cfg, script); err != nil {
+			printf("%s Startup script %s failed: %v\n", errColor("✘"), filepath.Base(script), err)
+			failed++
+			continue
+		}
+		printf("%s Startup script %s completed\n", success("✔"), filepath.Base(script))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d startup scripts failed", failed, len(cfg.StartupScripts))
+	}
+	return nil
+}
+
+func runStartupScript(cfg *Config, script string) error {
+	file, err := os.Open(script)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var output bytes.Buffer
+	if err := dockerClient.Stream(file, &output, &output, "exec", "-i", cfg.ContainerName,
+		"psql", "-U", cfg.Username, "-d", cfg.Database, "-v", "ON_ERROR_STOP=1", "-q", "-f", "-"); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// startupScriptsFromLabel returns the startup scripts recorded on a container
+func startupScriptsFromLabel(containerName string) ([]string, error) {
+	value, err := containerLabel(containerName, startupScriptsLabel)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	return strings.Split(value, ","), nil
+}
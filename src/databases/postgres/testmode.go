@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// Ports the test preset picks from, the IANA dynamic range
+const (
+	testPortMin      = 49152
+	testPortMax      = 65535
+	testPortAttempts = 20
+)
+
+// testReadinessTimeout is how long the test preset waits for the server
+// unless --wait-timeout says otherwise; a tmpfs database is up in seconds,
+// so a CI job shouldn't hang for the full default when something is wrong
+const testReadinessTimeout = 15 * time.Second
+
+// applyTestPreset configures a fast, disposable database for integration tests:
+// tmpfs storage, trust authentication and removal as soon as it stops
+func applyTestPreset(cfg *Config) error {
+	if cfg.Volume != "" || cfg.CopyFrom != "" {
+		return fmt.Errorf("--test keeps its data on tmpfs and cannot be combined with --volume or --copy-from")
+	}
+	cfg.Ephemeral = true
+	cfg.AutoRemove = true
+	cfg.TrustAuth = true
+	if cfg.ReadinessTimeout == 0 || cfg.ReadinessTimeout == defaultReadinessTimeout {
+		cfg.ReadinessTimeout = testReadinessTimeout
+	}
+	return nil
+}
+
+// randomHighPort picks a free port at random from the dynamic port range, so
+// parallel test runs rarely race for the same port
+func randomHighPort() (int, error) {
+	for i := 0; i < testPortAttempts; i++ {
+		port := testPortMin + rand.Intn(testPortMax-testPortMin+1)
+		if _, err := utils.FindAvailablePort(port, port); err == nil {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no available port found in range %d-%d after %d attempts", testPortMin, testPortMax, testPortAttempts)
+}
+
+// noFsyncArgs builds the server settings that trade durability for write
+// speed, for --no-fsync and for a tmpfs data directory, whose contents are
+// lost on stop anyway
+func noFsyncArgs(cfg *Config) []string {
+	if !cfg.NoFsync && !cfg.Ephemeral {
+		return nil
+	}
+	return []string{"-c", "fsync=off", "-c", "synchronous_commit=off", "-c", "full_page_writes=off"}
+}
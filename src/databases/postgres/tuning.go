@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+const (
+	// defaultMemFraction is the share of the host's RAM --mem-auto gives the container
+	defaultMemFraction = 0.25
+	// minAutoMemoryMB is the limit below which --mem-auto warns about a cramped database
+	minAutoMemoryMB = 256
+)
+
+var (
+	// walSizePattern matches a PostgreSQL size setting; a bare number means megabytes
+	walSizePattern = regexp.MustCompile(`^(\d+)(kB|MB|GB|TB)?$`)
+	// checkpointTimeoutPattern matches a PostgreSQL duration setting; a bare number means seconds
+	checkpointTimeoutPattern = regexp.MustCompile(`^(\d+)(ms|s|min|h|d)?$`)
+)
+
+// walSizeMegabytes converts max_wal_size units to megabytes
+var walSizeMegabytes = map[string]float64{"kB": 1.0 / 1024, "": 1, "MB": 1, "GB": 1024, "TB": 1024 * 1024}
+
+// checkpointTimeoutSeconds converts checkpoint_timeout units to seconds
+var checkpointTimeoutSeconds = map[string]float64{"ms": 0.001, "": 1, "s": 1, "min": 60, "h": 3600, "d": 86400}
+
+// validateTuning checks the checkpoint tuning settings against the units and
+// ranges PostgreSQL accepts, so a typo fails before the server refuses to start
+func validateTuning(cfg *Config) error {
+	if cfg.MaxWALSize != "" {
+		m := walSizePattern.FindStringSubmatch(cfg.MaxWALSize)
+		if m == nil {
+			return fmt.Errorf("invalid --max-wal-size %q, expected a size such as 1GB or 512MB", cfg.MaxWALSize)
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		if megabytes := n * walSizeMegabytes[m[2]]; megabytes < 32 {
+			return fmt.Errorf("invalid --max-wal-size %q, must be at least 32MB (two WAL segments)", cfg.MaxWALSize)
+		}
+	}
+
+	if cfg.CheckpointTimeout != "" {
+		m := checkpointTimeoutPattern.FindStringSubmatch(cfg.CheckpointTimeout)
+		if m == nil {
+			return fmt.Errorf("invalid --checkpoint-timeout %q, expected a duration such as 15min or 300s", cfg.CheckpointTimeout)
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		if seconds := n * checkpointTimeoutSeconds[m[2]]; seconds < 30 || seconds > 86400 {
+			return fmt.Errorf("invalid --checkpoint-timeout %q, must be between 30s and 1d", cfg.CheckpointTimeout)
+		}
+	}
+	if cfg.SharedBuffers != "" && !walSizePattern.MatchString(cfg.SharedBuffers) {
+		return fmt.Errorf("invalid shared-buffers %q, expected a size such as 256MB", cfg.SharedBuffers)
+	}
+	if cfg.MemFraction < 0 || cfg.MemFraction > 1 {
+		return fmt.Errorf("invalid --mem-fraction %g, expected a value between 0 and 1 such as 0.25", cfg.MemFraction)
+	}
+	return validateMemorySizes(cfg)
+}
+
+// validateMemorySizes checks the docker size strings of --memory, --memory-swap
+// and --shm-size, which docker would otherwise only reject at docker run
+func validateMemorySizes(cfg *Config) error {
+	memory, err := parseMemory(cfg.Memory)
+	if err != nil || memory < 0 {
+		return fmt.Errorf("invalid --memory %q, expected a size such as 512m or 1g", cfg.Memory)
+	}
+	if cfg.ShmSize != "" {
+		if shm, err := parseMemory(cfg.ShmSize); err != nil || shm <= 0 {
+			return fmt.Errorf("invalid --shm-size %q, expected a size such as 256m or 1g", cfg.ShmSize)
+		}
+	}
+	if cfg.MemorySwap == "" || cfg.MemorySwap == "-1" {
+		if cfg.MemorySwap != "" && cfg.Memory == "" && !cfg.MemAuto {
+			return fmt.Errorf("--memory-swap needs a --memory limit")
+		}
+		return nil
+	}
+	swap, err := parseMemory(cfg.MemorySwap)
+	if err != nil || swap <= 0 {
+		return fmt.Errorf("invalid --memory-swap %q, expected a size such as 2g, or -1 for unlimited swap", cfg.MemorySwap)
+	}
+	if cfg.Memory == "" && !cfg.MemAuto {
+		return fmt.Errorf("--memory-swap needs a --memory limit")
+	}
+	// --memory-swap is memory plus swap, so it can't be below the memory limit
+	if memory > 0 && swap < memory {
+		return fmt.Errorf("--memory-swap %s is smaller than --memory %s; it is the memory limit plus swap", cfg.MemorySwap, cfg.Memory)
+	}
+	return nil
+}
+
+// applyMemAuto sizes the memory limit as a fraction of the host's RAM and
+// shared_buffers as a quarter of that limit
+func applyMemAuto(cfg *Config) error {
+	if !cfg.MemAuto {
+		return nil
+	}
+	if cfg.Memory != "" {
+		return fmt.Errorf("--mem-auto and --memory are mutually exclusive")
+	}
+
+	fraction := cfg.MemFraction
+	if fraction == 0 {
+		fraction = defaultMemFraction
+	}
+	if fraction < 0 || fraction > 1 {
+		return fmt.Errorf("invalid --mem-fraction %g, expected a value between 0 and 1 such as 0.25", fraction)
+	}
+
+	total, err := utils.HostMemory()
+	if err != nil {
+		return fmt.Errorf("--mem-auto could not detect the host's memory: %v", err)
+	}
+	limitMB := int(float64(total) / (1024 * 1024) * fraction)
+	if limitMB < 1 {
+		return fmt.Errorf("--mem-auto computed a memory limit below 1MB; raise --mem-fraction or set --memory")
+	}
+
+	cfg.Memory = fmt.Sprintf("%dm", limitMB)
+	if cfg.SharedBuffers == "" && limitMB >= 4 {
+		cfg.SharedBuffers = fmt.Sprintf("%dMB", limitMB/4)
+	}
+	printf("%s Memory limit %s (%.0f%% of %d MB host RAM), shared_buffers %s\n",
+		info("ℹ"), cfg.Memory, fraction*100, total/(1024*1024), cfg.SharedBuffers)
+	if limitMB < minAutoMemoryMB {
+		printf("%s Warning: A %dMB memory limit is very small for PostgreSQL; raise --mem-fraction or set --memory\n", warn("⚠"), limitMB)
+	}
+	return nil
+}
+
+// tuningArgs builds the "-c" settings for the memory and checkpoint tuning flags
+func tuningArgs(cfg *Config) []string {
+	var args []string
+	if cfg.SharedBuffers != "" {
+		args = append(args, "-c", "shared_buffers="+cfg.SharedBuffers)
+	}
+	if cfg.MaxWALSize != "" {
+		args = append(args, "-c", "max_wal_size="+cfg.MaxWALSize)
+	}
+	if cfg.CheckpointTimeout != "" {
+		args = append(args, "-c", "checkpoint_timeout="+cfg.CheckpointTimeout)
+	}
+	return args
+}
+
+var (
+	// gucNamePattern matches a server parameter name; extension parameters
+	// are qualified, e.g. pg_stat_statements.max
+	gucNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+	// unsafeParamChars are shell metacharacters refused in --pg-param values
+	unsafeParamChars = "`$;&|<>\\\"'\n\r"
+)
+
+// validatePostgresParams checks the --pg-param names and values
+func validatePostgresParams(cfg *Config) error {
+	for key, value := range cfg.PostgresParams {
+		if !gucNamePattern.MatchString(key) {
+			return fmt.Errorf("invalid --pg-param %q, expected a parameter name such as work_mem", key)
+		}
+		if i := strings.IndexAny(value, unsafeParamChars); i != -1 {
+			return fmt.Errorf("invalid --pg-param %s=%s: character %q is not allowed", key, value, value[i])
+		}
+	}
+	return nil
+}
+
+// postgresParamArgs builds the "-c" settings for --pg-param. They come last,
+// so they override the settings other flags derive.
+func postgresParamArgs(cfg *Config) []string {
+	var args []string
+	for _, param := range sortedParams(cfg.PostgresParams) {
+		args = append(args, "-c", param)
+	}
+	return args
+}
+
+// sortedParams returns the parameters as key=value, sorted by key
+func sortedParams(params map[string]string) []string {
+	pairs := make([]string, 0, len(params))
+	for key, value := range params {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
@@ -0,0 +1,354 @@
+package postgres
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// UpgradeOptions controls how Upgrade moves a container to a new version
+type UpgradeOptions struct {
+	Version   string // image tag to upgrade to
+	BackupDir string // keep a pg_dumpall safety dump in this directory
+	Yes       bool   // don't ask for confirmation
+}
+
+// anonymousVolumePattern matches the generated names of anonymous volumes
+var anonymousVolumePattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Upgrade moves a container to another image version. Within a major version
+// the data directory is compatible, so the container is recreated on the new
+// image with the same data volume. Across major versions the data is dumped
+// with pg_dumpall, and the new container gets a fresh volume the dump is
+// restored into. Either way the new container keeps the old one's settings,
+// see inheritSettings, and the old container is kept, stopped, as <name>.bak
+// so that rollback can bring it back.
+func Upgrade(containerName string, opts UpgradeOptions) error {
+	if opts.Version == "" {
+		return fmt.Errorf("--version is required")
+	}
+	old, err := runningConfig(containerName)
+	if err != nil {
+		return err
+	}
+	if old.Version == opts.Version {
+		return fmt.Errorf("Container %s already runs version %s", containerName, opts.Version)
+	}
+	backup := containerName + backupSuffix
+	if exists, _ := containerExists(backup); exists {
+		return dberrors.Newf(dberrors.ErrContainerExists, "Container %s already exists; remove it (or roll back) before upgrading again", backup)
+	}
+
+	cfg, err := cloneConfig(old, containerName)
+	if err != nil {
+		return err
+	}
+	if err := inheritSettings(cfg, containerName); err != nil {
+		return err
+	}
+	cfg.Version = opts.Version
+	cfg.SkipPull = false
+	cfg.Password = old.Password
+	cfg.Port = old.Port
+	cfg.ExactPort = true
+
+	// The data mount is missing for tmpfs-backed containers, which can only
+	// be carried over through a dump
+	mount, _ := containerDataMount(containerName)
+	inPlace := mount != "" && majorVersion(old.Version) == majorVersion(opts.Version)
+	if inPlace {
+		cfg.Volume = mount
+	} else if mount != "" && !anonymousVolumePattern.MatchString(mount) {
+		cfg.Volume = fmt.Sprintf("%s-%s", mount, majorVersion(opts.Version))
+		// The new volume lives on the same storage as the old one
+		if driver, err := volumeDriver(mount); err == nil && driver != "local" {
+			cfg.VolumeDriver = driver
+		}
+	}
+
+	question := fmt.Sprintf("Upgrade %s from %s to %s", containerName, old.Version, opts.Version)
+	if inPlace {
+		question += " reusing its data volume"
+	} else {
+		question += " by dumping and restoring all databases"
+	}
+	if !opts.Yes {
+		ok, err := utils.Confirm(question + "?")
+		if err == utils.ErrNotInteractive {
+			return fmt.Errorf("refusing to upgrade %s without confirmation; pass --yes", containerName)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			printf("%s Nothing changed\n", info("ℹ"))
+			return nil
+		}
+	}
+
+	// A dump is needed to cross major versions, and kept when --backup-dir is given
+	dump := ""
+	if !inPlace || opts.BackupDir != "" {
+		if dump, err = dumpAll(old, opts.BackupDir); err != nil {
+			return err
+		}
+	}
+
+	if err := Stop(containerName); err != nil {
+		return err
+	}
+	if err := renameContainer(containerName, backup); err != nil {
+		return err
+	}
+	if err := CreateWithConfig(cfg); err != nil {
+		return restoreAfterFailedUpgrade(containerName, err)
+	}
+
+	if !inPlace {
+		if err := restoreAll(cfg, dump); err != nil {
+			return fmt.Errorf("%v; the dump is kept at %s and go-db rollback %s brings back the old container", err, dump, containerName)
+		}
+		if opts.BackupDir == "" {
+			os.Remove(dump)
+		}
+	}
+
+	printf("%s Upgraded %s from %s to %s\n", success("✔"), containerName, old.Version, opts.Version)
+	if dump != "" && opts.BackupDir != "" {
+		printf("%s Safety dump kept at %s\n", info("ℹ"), dump)
+	}
+	printf("%s The old container is kept as %s; go-db rollback %s goes back to it, go-db remove %s when you no longer need it\n",
+		info("ℹ"), backup, containerName, backup)
+	return nil
+}
+
+// inheritSettings copies what cloneConfig leaves out from the container
+// being upgraded: environment, labels, networks, mounts, SSL, server
+// parameters, restart policy, stop signal, healthcheck and shared memory. A
+// PgBouncer sidecar keeps working because the new container joins the
+// sidecar's network under the same name.
+func inheritSettings(cfg *Config, containerName string) error {
+	d, err := inspectContainer(containerName)
+	if err != nil {
+		return err
+	}
+	if d.HostConfig.AutoRemove {
+		return fmt.Errorf("Container %s is removed as soon as it stops, so it cannot be upgraded; back it up and create a new one instead", containerName)
+	}
+
+	// Variables baked into the image, such as PG_VERSION, change with it
+	imageEnv := map[string]bool{}
+	if output, err := dockerClient.Output("image", "inspect", "--format", `{{join .Config.Env "\n"}}`, d.ImageID); err == nil {
+		for _, entry := range strings.Split(string(output), "\n") {
+			key, _, _ := strings.Cut(entry, "=")
+			imageEnv[key] = true
+		}
+	}
+	for _, entry := range d.Config.Env {
+		key, value, _ := strings.Cut(entry, "=")
+		switch {
+		case imageEnv[key]:
+		case key == "POSTGRES_PASSWORD", key == "POSTGRES_USER", key == "POSTGRES_DB", key == "TZ", key == "LANG":
+		case key == "POSTGRES_HOST_AUTH_METHOD" && value == "trust":
+			cfg.TrustAuth = true
+		default:
+			if cfg.Environment == nil {
+				cfg.Environment = map[string]string{}
+			}
+			cfg.Environment[key] = value
+		}
+	}
+
+	if cfg.Labels, err = userLabels(containerName); err != nil {
+		return err
+	}
+	cfg.Prefix = d.Config.Labels[prefixLabel]
+	if scripts := d.Config.Labels[startupScriptsLabel]; scripts != "" {
+		cfg.StartupScripts = strings.Split(scripts, ",")
+	}
+
+	for network := range d.NetworkSettings.Networks {
+		if network != "bridge" {
+			cfg.Networks = append(cfg.Networks, network)
+		}
+	}
+	sort.Strings(cfg.Networks)
+
+	// The data volume is chosen by Upgrade, and init scripts only run on an
+	// empty data directory, which the dump is restored into
+	for _, m := range d.Mounts {
+		switch {
+		case m.Destination == dataDir, strings.HasPrefix(m.Destination, "/docker-entrypoint-initdb.d/"):
+		case m.Destination == "/var/lib/postgresql/server.crt":
+			cfg.SSLCert = m.Source
+		case m.Destination == sslKeySource, m.Destination == "/var/lib/postgresql/server.key":
+			cfg.SSLKey = m.Source
+		case m.Destination == "/var/lib/postgresql/root.crt":
+			cfg.SSLRootCert = m.Source
+		case m.Type == "bind" || m.Type == "volume":
+			source := m.Source
+			if m.Type == "volume" {
+				source = m.Name
+			}
+			mount := source + ":" + m.Destination
+			if !m.RW {
+				mount += ":ro"
+			}
+			cfg.ExtraMounts = append(cfg.ExtraMounts, mount)
+		}
+	}
+	if cfg.SSLCert != "" && cfg.SSLKey != "" {
+		cfg.SSLMode = "require"
+	}
+	if _, ok := d.HostConfig.Tmpfs[dataDir]; ok {
+		cfg.Ephemeral = true
+	}
+
+	// Every "-c name=value" becomes a --pg-param, except the SSL settings
+	// serverArgs derives from the mounts above
+	cmd := d.Config.Cmd
+	if len(cmd) >= 4 && cmd[0] == "-c" && cmd[2] == "go-db" {
+		cmd = cmd[4:]
+	} else if len(cmd) > 0 && cmd[0] == "postgres" {
+		cmd = cmd[1:]
+	}
+	for i := 0; i+1 < len(cmd); i++ {
+		if cmd[i] != "-c" {
+			continue
+		}
+		key, value, ok := strings.Cut(cmd[i+1], "=")
+		i++
+		if !ok || key == "ssl" || strings.HasPrefix(key, "ssl_") {
+			continue
+		}
+		if cfg.PostgresParams == nil {
+			cfg.PostgresParams = map[string]string{}
+		}
+		cfg.PostgresParams[key] = value
+	}
+
+	if policy := d.HostConfig.RestartPolicy; policy.Name != "" && policy.Name != "no" {
+		cfg.RestartPolicy = policy.Name
+		if policy.Name == "on-failure" && policy.MaximumRetryCount > 0 {
+			cfg.RestartPolicy = fmt.Sprintf("on-failure:%d", policy.MaximumRetryCount)
+		}
+	}
+	cfg.StopSignal = d.Config.StopSignal
+	if hc := d.Config.Healthcheck; hc != nil {
+		if hc.Interval > 0 {
+			cfg.HealthInterval = hc.Interval.String()
+		}
+		if hc.Timeout > 0 {
+			cfg.HealthTimeout = hc.Timeout.String()
+		}
+		if hc.StartPeriod > 0 {
+			cfg.HealthStartPeriod = hc.StartPeriod.String()
+		}
+		cfg.HealthRetries = hc.Retries
+	}
+	if d.HostConfig.ShmSize > 0 {
+		cfg.ShmSize = fmt.Sprintf("%db", d.HostConfig.ShmSize)
+	}
+	if d.HostConfig.MemorySwap > 0 {
+		cfg.MemorySwap = fmt.Sprintf("%db", d.HostConfig.MemorySwap)
+	} else if d.HostConfig.MemorySwap == -1 {
+		cfg.MemorySwap = "-1"
+	}
+	return nil
+}
+
+// restoreAfterFailedUpgrade removes what a failed upgrade created and puts the
+// old container back under its name
+func restoreAfterFailedUpgrade(containerName string, cause error) error {
+	if exists, _ := containerExists(containerName); exists {
+		dockerClient.Run("rm", "-f", containerName)
+	}
+	if err := renameContainer(containerName+backupSuffix, containerName); err != nil {
+		return fmt.Errorf("upgrade failed: %v; restoring the old container also failed: %v", cause, err)
+	}
+	if err := Start(containerName); err != nil {
+		return fmt.Errorf("upgrade failed: %v; the old container is back but did not start: %v", cause, err)
+	}
+	return fmt.Errorf("upgrade failed, the old container was restored: %v", cause)
+}
+
+// majorVersion returns the major version of an image tag, e.g. 16 for 16.2-alpine
+func majorVersion(version string) string {
+	version = strings.SplitN(version, "-", 2)[0]
+	return strings.SplitN(version, ".", 2)[0]
+}
+
+// dumpAll writes a pg_dumpall of the whole cluster, roles included, to dir or
+// a temporary file when dir is empty, and returns its path
+func dumpAll(cfg *Config, dir string) (string, error) {
+	var file *os.File
+	var err error
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", fmt.Errorf("Failed to create backup directory: %v", err)
+		}
+		name := fmt.Sprintf("%s-%s-%s.sql", cfg.ContainerName, cfg.Version, time.Now().Format("20060102-150405"))
+		file, err = os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	} else {
+		file, err = os.CreateTemp("", cfg.ContainerName+"-upgrade-*.sql")
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to create dump file: %v", err)
+	}
+	defer file.Close()
+
+	printf("%s Dumping all databases of %s...\n", info("ℹ"), cfg.ContainerName)
+	if err := dockerClient.Stream(nil, file, os.Stderr, "exec", cfg.ContainerName, "pg_dumpall", "-U", cfg.Username, "-l", cfg.Database); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("pg_dumpall failed: %v", err)
+	}
+	return file.Name(), nil
+}
+
+// restoreAll loads a pg_dumpall file into a fresh container. The container's
+// own role and database already exist, so their statements are skipped; any
+// other error stops the restore and fails the upgrade.
+func restoreAll(cfg *Config, dump string) error {
+	data, err := os.ReadFile(dump)
+	if err != nil {
+		return fmt.Errorf("Failed to open dump: %v", err)
+	}
+
+	roles, err := psqlQuery(cfg, "SELECT rolname FROM pg_roles")
+	if err != nil {
+		return fmt.Errorf("Failed to list existing roles: %v", err)
+	}
+	databases, err := psqlQuery(cfg, "SELECT datname FROM pg_database")
+	if err != nil {
+		return fmt.Errorf("Failed to list existing databases: %v", err)
+	}
+	existingRoles := make(map[string]bool)
+	for _, role := range strings.Split(roles, "\n") {
+		existingRoles[role] = true
+	}
+	existingDatabases := make(map[string]bool)
+	for _, db := range strings.Split(databases, "\n") {
+		existingDatabases[db] = true
+	}
+	script, _ := skipExisting(string(data), map[string]map[string]bool{
+		"CREATE ROLE ":     existingRoles,
+		"ALTER ROLE ":      existingRoles,
+		"CREATE DATABASE ": existingDatabases,
+	})
+
+	printf("%s Restoring all databases into %s...\n", info("ℹ"), cfg.ContainerName)
+	var output bytes.Buffer
+	if err := dockerClient.Stream(strings.NewReader(script), &output, &output, "exec", "-i", cfg.ContainerName,
+		"psql", "-U", cfg.Username, "-d", cfg.Database, "-v", "ON_ERROR_STOP=1", "-q"); err != nil {
+		return fmt.Errorf("Failed to restore dump: %v: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
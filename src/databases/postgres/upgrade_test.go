@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/awade12/go-db/src/docker"
+)
+
+// pipeStdin replaces stdin with the read end of a pipe, as in CI or with
+// piped input, so no prompt can be answered
+func pipeStdin(t *testing.T) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+		w.Close()
+	})
+}
+
+func TestUpgradeConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		yes     bool
+		wantErr string
+		dumped  bool
+	}{
+		{name: "refuses without a terminal", yes: false, wantErr: "pass --yes", dumped: false},
+		{name: "--yes skips the prompt", yes: true, wantErr: "pg_dumpall failed", dumped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeStdin(t)
+			fake, _ := useFakeClient(t, map[string]docker.FakeResponse{
+				"ps -a --filter name=^shop$ ":                                      {Output: "Up 1 hour\n"},
+				"inspect --format {{.Config.Image}} shop":                          {Output: "postgres:15\n"},
+				"inspect --format {{.Image}}\t{{json .Config.Labels}} shop":        {Output: "sha256:abc\t{}\n"},
+				"inspect --format {{.HostConfig.Memory}} {{.HostConfig.NanoCpus}}": {Output: "0 0\n"},
+				"inspect --type container shop":                                    {Output: `[{"Name": "/shop"}]`},
+				"exec shop pg_dumpall":                                             {Err: errFake},
+			})
+
+			err := Upgrade("shop", UpgradeOptions{Version: "16", Yes: tt.yes})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Upgrade() error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if got := fake.Ran("exec shop pg_dumpall"); got != tt.dumped {
+				t.Errorf("pg_dumpall ran = %v, want %v; commands:\n%s", got, tt.dumped, strings.Join(fake.Calls(), "\n"))
+			}
+		})
+	}
+}
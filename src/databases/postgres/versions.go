@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+const (
+	tagsURL      = "https://hub.docker.com/v2/repositories/library/postgres/tags?page_size=100"
+	tagsMaxPages = 10             // the newest 1000 tags cover every supported release
+	tagsCacheTTL = 24 * time.Hour // how long a fetched tag list is reused
+)
+
+// versionTag matches plain release tags such as 16 or 15.4, leaving out
+// variants like 16-alpine or 17beta1
+var versionTag = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// tagsCache is the on-disk cache of Docker Hub tags
+type tagsCache struct {
+	Fetched time.Time `json:"fetched"`
+	Tags    []string  `json:"tags"`
+}
+
+// Versions prints the PostgreSQL versions available on Docker Hub, grouped by
+// major version. Tags are cached for a day; offline, a stale cache or the
+// locally pulled images are used instead.
+func Versions() error {
+	cachePath := ""
+	if dir, err := utils.ConfigDir("cache"); err == nil {
+		cachePath = filepath.Join(dir, "postgres-tags.json")
+	}
+
+	cache, cacheErr := readTagsCache(cachePath)
+	source := "Docker Hub (cached)"
+	if cacheErr != nil || time.Since(cache.Fetched) > tagsCacheTTL {
+		tags, err := fetchTags()
+		switch {
+		case err == nil:
+			cache = tagsCache{Fetched: time.Now(), Tags: tags}
+			source = "Docker Hub"
+			if cachePath != "" {
+				writeTagsCache(cachePath, cache)
+			}
+		case cacheErr == nil:
+			printf("%s Warning: Could not reach Docker Hub (%v), showing tags cached %s\n",
+				warn("⚠"), err, cache.Fetched.Format("2006-01-02 15:04"))
+		default:
+			local, localErr := localTags()
+			if localErr != nil || len(local) == 0 {
+				return fmt.Errorf("Could not reach Docker Hub and no tags are cached: %v", err)
+			}
+			printf("%s Warning: Could not reach Docker Hub (%v), showing locally pulled images only\n", warn("⚠"), err)
+			cache = tagsCache{Tags: local}
+			source = "local images"
+		}
+	}
+
+	majors, minors := groupVersions(cache.Tags)
+	if len(majors) == 0 {
+		printf("\n  %s No PostgreSQL versions found\n\n", warn("⚠"))
+		return nil
+	}
+
+	printf("\n%s PostgreSQL versions from %s\n\n", info("📦"), source)
+	for _, major := range majors {
+		printf("  %s %-4s %s\n", info("→"), major, strings.Join(minors[major], ", "))
+	}
+	printf("\n%s Use one with: go-db create-custom postgres --version <version>\n\n", info("ℹ"))
+	return nil
+}
+
+// fetchTags pages through the Docker Hub tags API and returns the release tags
+func fetchTags() ([]string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var tags []string
+	url := tagsURL
+	for page := 0; page < tagsMaxPages && url != ""; page++ {
+		resp, err := client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+
+		var body struct {
+			Next    string `json:"next"`
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("docker hub returned %s", resp.Status)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags: %v", err)
+		}
+
+		for _, result := range body.Results {
+			if versionTag.MatchString(result.Name) {
+				tags = append(tags, result.Name)
+			}
+		}
+		url = body.Next
+	}
+	return tags, nil
+}
+
+// localTags returns the release tags of the postgres images pulled locally
+func localTags() ([]string, error) {
+	output, err := dockerClient.Output("images", "postgres", "--format", "{{.Tag}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, tag := range strings.Fields(string(output)) {
+		if versionTag.MatchString(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func readTagsCache(path string) (tagsCache, error) {
+	var cache tagsCache
+	if path == "" {
+		return cache, fmt.Errorf("no cache directory")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	err = json.Unmarshal(data, &cache)
+	return cache, err
+}
+
+// writeTagsCache stores the tag list; failing to cache is not an error
+func writeTagsCache(path string, cache tagsCache) {
+	if data, err := json.Marshal(cache); err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+// groupVersions returns the major versions, newest first, and for each major
+// its tags, newest first (the major tag itself tracks the latest minor)
+func groupVersions(tags []string) ([]string, map[string][]string) {
+	seen := make(map[string]bool)
+	minors := make(map[string][]string)
+	var majors []string
+	for _, tag := range tags {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		major := strings.SplitN(tag, ".", 2)[0]
+		if _, ok := minors[major]; !ok {
+			majors = append(majors, major)
+		}
+		minors[major] = append(minors[major], tag)
+	}
+
+	sort.Slice(majors, func(i, j int) bool { return versionLess(majors[j], majors[i]) })
+	for _, major := range majors {
+		list := minors[major]
+		sort.Slice(list, func(i, j int) bool { return versionLess(list[j], list[i]) })
+	}
+	return majors, minors
+}
+
+// versionLess compares dotted version strings numerically
+func versionLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
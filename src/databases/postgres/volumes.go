@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// managedLabel marks the named volumes go-db created, so cleanup never touches
+// volumes created by other tools
+const managedLabel = databases.ManagedLabel
+
+// createManagedVolume creates a named volume stamped with the managed label,
+// with docker's default driver unless driver is set
+func createManagedVolume(volume, driver string) error {
+	args := []string{"volume", "create", "--label", managedLabel + "=true"}
+	if driver != "" {
+		args = append(args, "--driver", driver)
+	}
+	output, err := dockerClient.CombinedOutput(append(args, volume)...)
+	if err != nil {
+		return fmt.Errorf("failed to create volume %s: %v: %s", volume, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ensureVolume creates a named volume as a managed volume unless it already exists.
+// Host paths and existing volumes are left to docker, but an existing volume
+// on another driver than the requested one is refused rather than silently used.
+func ensureVolume(volume, driver string) error {
+	if !isNamedVolume(volume) {
+		return nil
+	}
+	existing, err := volumeDriver(volume)
+	if err != nil {
+		return createManagedVolume(volume, driver)
+	}
+	if driver != "" && existing != driver {
+		return fmt.Errorf("volume %s already exists with the %s driver, not %s", volume, existing, driver)
+	}
+	return nil
+}
+
+// volumeDriver returns the driver of an existing named volume
+func volumeDriver(volume string) (string, error) {
+	output, err := dockerClient.Output("volume", "inspect", "--format", "{{.Driver}}", volume)
+	if err != nil {
+		return "", fmt.Errorf("volume %s does not exist", volume)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// describeVolume says how --volume is mounted, for the connection details
+func describeVolume(cfg *Config) string {
+	if !isNamedVolume(cfg.Volume) {
+		return "bind mount"
+	}
+	driver := cfg.VolumeDriver
+	if driver == "" {
+		driver, _ = volumeDriver(cfg.Volume)
+	}
+	if driver == "" {
+		return "named volume"
+	}
+	return fmt.Sprintf("named volume, %s driver", driver)
+}
+
+// isManagedVolume reports whether a named volume was created by go-db
+func isManagedVolume(volume string) bool {
+	output, err := dockerClient.Output("volume", "inspect", "--format",
+		fmt.Sprintf("{{index .Labels %q}}", managedLabel), volume)
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// danglingVolumes returns the go-db volumes no container uses any more
+func danglingVolumes() ([]string, error) {
+	output, err := dockerClient.Output("volume", "ls", "-q",
+		"--filter", "dangling=true",
+		"--filter", "label="+managedLabel+"=true")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %v", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// PruneVolumes lists the dangling volumes created by go-db and, when cleanup
+// is set, removes them after asking for confirmation unless yes is set
+func PruneVolumes(cleanup, yes bool) error {
+	volumes, err := danglingVolumes()
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		printf("%s No dangling go-db volumes found\n", success("✔"))
+		return nil
+	}
+
+	if !cleanup || !yes {
+		printf("%s Dangling go-db volumes:\n", info("ℹ"))
+		for _, volume := range volumes {
+			printf("  %s %s\n", info("→"), volume)
+		}
+	}
+	if !cleanup {
+		printf("%s Run with --cleanup-volumes to remove them\n", info("ℹ"))
+		return nil
+	}
+	if !yes {
+		ok, err := utils.Confirm(fmt.Sprintf("Remove these %d volumes and the data in them?", len(volumes)))
+		if err == utils.ErrNotInteractive {
+			return fmt.Errorf("refusing to remove volumes without confirmation; pass --yes")
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			printf("%s Nothing removed\n", info("ℹ"))
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, volume := range volumes {
+		if output, err := dockerClient.CombinedOutput("volume", "rm", volume); err != nil {
+			printf("%s Failed to remove volume %s: %s\n", errColor("✘"), volume, strings.TrimSpace(string(output)))
+			failed++
+			continue
+		}
+		printf("%s Removed volume %s\n", success("✔"), volume)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d volumes could not be removed", failed, len(volumes))
+	}
+	return nil
+}
+
+// RemoveWithVolume removes a container together with its data volume, if
+// that volume was created by go-db
+func RemoveWithVolume(containerName string, force bool) error {
+	volume, _ := containerDataMount(containerName)
+
+	if err := Remove(containerName, force); err != nil {
+		return err
+	}
+
+	if !isNamedVolume(volume) {
+		return nil
+	}
+	if !isManagedVolume(volume) {
+		printf("%s Keeping volume %s, it was not created by go-db\n", info("ℹ"), volume)
+		return nil
+	}
+	if output, err := dockerClient.CombinedOutput("volume", "rm", volume); err != nil {
+		return fmt.Errorf("Failed to remove volume %s: %s", volume, strings.TrimSpace(string(output)))
+	}
+	printf("%s Volume %s removed successfully\n", success("✔"), volume)
+	return nil
+}
@@ -0,0 +1,124 @@
+package databases
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// portScanSize is how many ports after the default are tried when it's taken
+const portScanSize = 100
+
+// Labels stamped on every container go-db creates, so list can find them
+// whatever their image and tell their types apart
+const (
+	ManagedLabel = "go-db.managed"
+	TypeLabel    = "go-db.type"
+)
+
+// DatabaseType describes a database type go-db can create
+type DatabaseType struct {
+	Name           string   // type name used on the command line, e.g. postgres
+	DisplayName    string   // name shown in messages, e.g. PostgreSQL
+	Image          string   // default image repository
+	DefaultVersion string   // image tag used unless --version is given
+	DefaultPort    int      // host port tried first
+	InternalPort   int      // port the server listens on inside the container
+	ReadyCommand   []string // readiness probe run inside the container; callers add credentials
+}
+
+// Registered database types
+var (
+	Postgres = DatabaseType{
+		Name:           "postgres",
+		DisplayName:    "PostgreSQL",
+		Image:          "postgres",
+		DefaultVersion: "15",
+		DefaultPort:    5432,
+		InternalPort:   5432,
+		ReadyCommand:   []string{"pg_isready"},
+	}
+	MySQL = DatabaseType{
+		Name:           "mysql",
+		DisplayName:    "MySQL",
+		Image:          "mysql",
+		DefaultVersion: "8.0",
+		DefaultPort:    3306,
+		InternalPort:   3306,
+		ReadyCommand:   []string{"mysqladmin", "ping", "--silent"},
+	}
+	MariaDB = DatabaseType{
+		Name:           "mariadb",
+		DisplayName:    "MariaDB",
+		Image:          "mariadb",
+		DefaultVersion: "11.4",
+		DefaultPort:    3306,
+		InternalPort:   3306,
+		ReadyCommand:   []string{"mariadb-admin", "ping", "--silent"},
+	}
+)
+
+// types lists the registered database types. The per-type packages implement
+// the commands; adding a type starts with an entry here.
+var types = []DatabaseType{Postgres, MySQL, MariaDB}
+
+// Lookup returns the database type registered under name, ignoring case
+func Lookup(name string) (DatabaseType, error) {
+	for _, t := range types {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return DatabaseType{}, fmt.Errorf("Unsupported database type: %s (supported: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of the registered database types
+func Names() []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// DefaultPortString returns the default host port as a flag value
+func (t DatabaseType) DefaultPortString() string {
+	return strconv.Itoa(t.DefaultPort)
+}
+
+// LabelArgs returns the docker run arguments marking a container as a
+// go-db managed container of this type
+func (t DatabaseType) LabelArgs() []string {
+	return []string{
+		"--label", ManagedLabel + "=true",
+		"--label", fmt.Sprintf("%s=%s", TypeLabel, t.Name),
+	}
+}
+
+// LabelFilter returns the docker ps filter matching the containers of this type
+func (t DatabaseType) LabelFilter() string {
+	return fmt.Sprintf("label=%s=%s", TypeLabel, t.Name)
+}
+
+// PortMapping returns the docker -p value publishing the server on hostPort
+func (t DatabaseType) PortMapping(hostPort string) string {
+	return fmt.Sprintf("%s:%d", hostPort, t.InternalPort)
+}
+
+// FindPort returns the first available host port among count ports from
+// start; zero values stand for the type's default port and the usual scan size
+func (t DatabaseType) FindPort(start, count int) (int, error) {
+	if start == 0 {
+		start = t.DefaultPort
+	}
+	if count == 0 {
+		count = portScanSize
+	}
+	end := start + count - 1
+	if end > 65535 {
+		end = 65535
+	}
+	return utils.FindAvailablePort(start, end)
+}
@@ -0,0 +1,39 @@
+package databases
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+	// The zone names are checked against Go's embedded copy of the tz
+	// database, so the check doesn't depend on the host having tzdata
+	_ "time/tzdata"
+)
+
+// utcOffsetPattern matches the offset forms accepted besides zone names, e.g.
+// UTC+3 or GMT-05:30. As in the POSIX TZ variable, UTC+3 is three hours west
+// of (behind) UTC.
+var utcOffsetPattern = regexp.MustCompile(`^(UTC|GMT)([+-])(\d{1,2})(:[0-5]\d)?$`)
+
+// ValidateTimezone checks a TZ value before a container is created with it:
+// a tz database name such as Europe/Berlin, UTC, GMT, or an offset from them.
+// A typo would otherwise leave the container silently running on UTC.
+func ValidateTimezone(tz string) error {
+	switch tz {
+	case "", "UTC", "GMT":
+		return nil
+	case "Local":
+		return fmt.Errorf("invalid --timezone %q: name the zone, e.g. Europe/Berlin", tz)
+	}
+	if m := utcOffsetPattern.FindStringSubmatch(tz); m != nil {
+		var hours int
+		fmt.Sscan(m[3], &hours)
+		if hours > 24 {
+			return fmt.Errorf("invalid --timezone %q: the offset must be at most 24 hours", tz)
+		}
+		return nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("unknown --timezone %q: not a tz database zone; use a name such as Europe/Berlin or America/Chicago, UTC, or an offset such as UTC+3", tz)
+	}
+	return nil
+}
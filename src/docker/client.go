@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// DockerClient runs container CLI commands. The arguments are those that
+// follow the program name, e.g. Run("stop", "mydb") for docker stop mydb.
+// Code that talks to docker through it can be exercised with a fake client
+// instead of a running daemon.
+type DockerClient interface {
+	// Run runs a command, discarding its output
+	Run(args ...string) error
+	// Output runs a command and returns its standard output
+	Output(args ...string) ([]byte, error)
+	// CombinedOutput runs a command and returns its standard output and error
+	CombinedOutput(args ...string) ([]byte, error)
+	// CombinedOutputContext is CombinedOutput for a command that is killed
+	// when ctx is done, e.g. a readiness probe with a deadline
+	CombinedOutputContext(ctx context.Context, args ...string) ([]byte, error)
+	// Inspect returns docker inspect's output for a container, rendered with a
+	// Go template, or docker's JSON when format is empty
+	Inspect(name, format string) ([]byte, error)
+	// PS lists the containers, stopped ones included, that match every filter
+	// (e.g. label=go-db.managed=true), one line per container rendered with format
+	PS(format string, filters ...string) ([]byte, error)
+	// Stream runs a command attached to the given stdin, stdout and stderr,
+	// any of which may be nil, e.g. to pipe a dump in or out of a container
+	// or to attach a terminal to psql
+	Stream(stdin io.Reader, stdout, stderr io.Writer, args ...string) error
+}
+
+// ExecClient is the DockerClient that runs the selected container CLI,
+// honoring --runtime and --verbose
+type ExecClient struct{}
+
+// Run runs a command, discarding its output
+func (ExecClient) Run(args ...string) error {
+	return utils.RunDocker(args...).Run()
+}
+
+// Output runs a command and returns its standard output
+func (ExecClient) Output(args ...string) ([]byte, error) {
+	return utils.RunDocker(args...).Output()
+}
+
+// CombinedOutput runs a command and returns its standard output and error
+func (ExecClient) CombinedOutput(args ...string) ([]byte, error) {
+	return utils.RunDocker(args...).CombinedOutput()
+}
+
+// CombinedOutputContext runs a command that is killed when ctx is done and
+// returns its standard output and error
+func (ExecClient) CombinedOutputContext(ctx context.Context, args ...string) ([]byte, error) {
+	return utils.RunDockerContext(ctx, args...).CombinedOutput()
+}
+
+// Inspect runs docker inspect on a container
+func (c ExecClient) Inspect(name, format string) ([]byte, error) {
+	args := []string{"inspect"}
+	if format != "" {
+		args = append(args, "--format", format)
+	}
+	return c.Output(append(args, name)...)
+}
+
+// PS runs docker ps -a with the given filters and format
+func (c ExecClient) PS(format string, filters ...string) ([]byte, error) {
+	args := []string{"ps", "-a"}
+	for _, filter := range filters {
+		args = append(args, "--filter", filter)
+	}
+	return c.Output(append(args, "--format", format)...)
+}
+
+// Stream runs a command with the given standard streams; nil ones are
+// connected to the null device
+func (ExecClient) Stream(stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	cmd := utils.RunDocker(args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+	return cmd.Run()
+}
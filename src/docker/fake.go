@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FakeResponse is what a FakeClient answers to a command
+type FakeResponse struct {
+	Output string // written to stdout, or returned by Output and CombinedOutput
+	Err    error  // returned as the command's error
+}
+
+// FakeClient is a DockerClient for tests that never runs anything. It answers
+// each command with the response whose key is the longest prefix of the
+// command line (the arguments joined by spaces, e.g. "ps -a --filter
+// label=go-db.type=postgres"); commands without a response succeed with no
+// output. Every command is recorded in Calls.
+type FakeClient struct {
+	Responses map[string]FakeResponse
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+// NewFakeClient returns a FakeClient answering with the given responses
+func NewFakeClient(responses map[string]FakeResponse) *FakeClient {
+	if responses == nil {
+		responses = make(map[string]FakeResponse)
+	}
+	return &FakeClient{Responses: responses}
+}
+
+// Calls returns the command lines run so far, in order
+func (f *FakeClient) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lines := make([]string, len(f.calls))
+	for i, args := range f.calls {
+		lines[i] = strings.Join(args, " ")
+	}
+	return lines
+}
+
+// Ran reports whether a command line starting with prefix was run
+func (f *FakeClient) Ran(prefix string) bool {
+	for _, line := range f.Calls() {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// respond records a command and looks up its response
+func (f *FakeClient) respond(args []string) FakeResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), args...))
+
+	line := strings.Join(args, " ")
+	best, found := "", false
+	for key := range f.Responses {
+		if strings.HasPrefix(line, key) && (!found || len(key) > len(best)) {
+			best, found = key, true
+		}
+	}
+	if !found {
+		return FakeResponse{}
+	}
+	return f.Responses[best]
+}
+
+// Run records a command and returns its error
+func (f *FakeClient) Run(args ...string) error {
+	return f.respond(args).Err
+}
+
+// Output records a command and returns its output and error
+func (f *FakeClient) Output(args ...string) ([]byte, error) {
+	r := f.respond(args)
+	return []byte(r.Output), r.Err
+}
+
+// CombinedOutput records a command and returns its output and error
+func (f *FakeClient) CombinedOutput(args ...string) ([]byte, error) {
+	return f.Output(args...)
+}
+
+// CombinedOutputContext is CombinedOutput, failing once ctx is done
+func (f *FakeClient) CombinedOutputContext(ctx context.Context, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Output(args...)
+}
+
+// Inspect records the docker inspect command ExecClient would run
+func (f *FakeClient) Inspect(name, format string) ([]byte, error) {
+	args := []string{"inspect"}
+	if format != "" {
+		args = append(args, "--format", format)
+	}
+	return f.Output(append(args, name)...)
+}
+
+// PS records the docker ps command ExecClient would run
+func (f *FakeClient) PS(format string, filters ...string) ([]byte, error) {
+	args := []string{"ps", "-a"}
+	for _, filter := range filters {
+		args = append(args, "--filter", filter)
+	}
+	return f.Output(append(args, "--format", format)...)
+}
+
+// Stream records a command, drains stdin and writes the output to stdout
+func (f *FakeClient) Stream(stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	r := f.respond(args)
+	if stdin != nil {
+		io.Copy(io.Discard, stdin)
+	}
+	if stdout != nil {
+		io.WriteString(stdout, r.Output)
+	}
+	return r.Err
+}
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure callers can tell apart with errors.Is, e.g.
+// errors.Is(err, ErrContainerExists), whatever the message says
+var (
+	ErrContainerExists     = errors.New("container already exists")
+	ErrContainerNotFound   = errors.New("container does not exist")
+	ErrContainerNotRunning = errors.New("container is not running")
+	ErrDockerMissing       = errors.New("container runtime is not installed")
+	ErrPortUnavailable     = errors.New("port is not available")
+)
+
+// Error is a failure of one of the kinds above. Message is the plain-text
+// sentence shown to the user; coloring is left to the CLI.
+type Error struct {
+	Kind    error // one of the Err* values
+	Message string
+	Cause   error // the underlying error, if any
+}
+
+// Error returns the message, followed by the cause when there is one
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the cause, so errors.Is and errors.As also see through it
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the error is of the target kind
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Newf returns an error of the given kind with a formatted message
+func Newf(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrapf returns an error of the given kind with a formatted message that
+// wraps cause
+func Wrapf(kind, cause error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Cause: cause}
+}
@@ -0,0 +1,76 @@
+package flags
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases/postgres"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// loadConfigFile reads a YAML, TOML or JSON config file (chosen by its
+// extension) on top of cfg. Its keys are the create-custom flag names.
+func loadConfigFile(path string, cfg *postgres.Config) error {
+	values, err := utils.ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if err := utils.DecodeConfig(values, cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// readPasswordFile reads a password from a file such as a docker or
+// Kubernetes secret, without the trailing newline editors add
+func readPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --password-file: %v", err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("--password-file %s is empty", path)
+	}
+	return password, nil
+}
+
+// copyConfig deep-copies src into dst, so decoding a file into dst cannot
+// reuse the backing arrays of src's slices
+func copyConfig(src, dst *postgres.Config) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// overlaySetFlags copies the values of the flags explicitly set in fs (on the
+// command line or through GODB_* variables) from src onto dst. Config's JSON
+// keys are the flag names, which links each flag to its field.
+func overlaySetFlags(fs *flag.FlagSet, src, dst *postgres.Config) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	set := make(map[string]json.RawMessage)
+	fs.Visit(func(fl *flag.Flag) {
+		if value, ok := values[fl.Name]; ok {
+			set[fl.Name] = value
+		}
+	})
+
+	data, err = json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
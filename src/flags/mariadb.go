@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"flag"
+
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/databases/mariadb"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// MariaDBFlags holds all flag sets for MariaDB operations
+type MariaDBFlags struct {
+	CreateFlags  *flag.FlagSet
+	CustomFlags  *flag.FlagSet
+	Version      *string
+	Port         *string
+	Password     *string
+	RootPassword *string
+	User         *string
+	DBName       *string
+	Volume       *string
+	Memory       *string
+	CPU          *string
+	Name         *string
+	Timezone     *string
+}
+
+// NewMariaDBFlags initializes all MariaDB-related flags
+func NewMariaDBFlags() *MariaDBFlags {
+	f := &MariaDBFlags{
+		CreateFlags: flag.NewFlagSet("create", flag.ExitOnError),
+		CustomFlags: flag.NewFlagSet("create-custom", flag.ExitOnError),
+	}
+
+	// Initialize create-custom flags
+	f.Version = f.CustomFlags.String("version", databases.MariaDB.DefaultVersion, "MariaDB version")
+	f.Port = f.CustomFlags.String("port", databases.MariaDB.DefaultPortString(), "Port to expose")
+	f.Password = f.CustomFlags.String("password", "", "Password of --user (default: generated)")
+	f.RootPassword = f.CustomFlags.String("root-password", "", "Root password (default: --password)")
+	f.User = f.CustomFlags.String("user", "root", "Database user; any user other than root is created with access to --db")
+	f.DBName = f.CustomFlags.String("db", "", "Database name (default: --name)")
+	f.Volume = f.CustomFlags.String("volume", "", "Data volume path")
+	f.Memory = f.CustomFlags.String("memory", "", "Memory limit")
+	f.CPU = f.CustomFlags.String("cpu", "", "CPU limit")
+	f.Name = f.CustomFlags.String("name", "", "Container name")
+	f.Timezone = f.CustomFlags.String("timezone", "UTC", "Container timezone")
+
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags} {
+		addRuntimeFlags(fs)
+	}
+
+	setUsage(f.CreateFlags, commandHelp{
+		usage:       "create mariadb <name>",
+		description: "Create a MariaDB container with default settings and a generated root password.",
+		examples:    []string{"create mariadb mydb"},
+	})
+	setUsage(f.CustomFlags, commandHelp{
+		usage:       "create-custom mariadb --name <name> [flags]",
+		description: "Create a MariaDB container with custom settings.",
+		examples: []string{
+			"create-custom mariadb --name mydb --version 10.11 --port 3307",
+			"create-custom mariadb --name mydb --user app --password secret --volume mydb-data",
+		},
+	})
+	return f
+}
+
+// BuildConfig creates a MariaDB configuration from the flags
+func (f *MariaDBFlags) BuildConfig() *mariadb.Config {
+	database := *f.DBName
+	if database == "" {
+		database = *f.Name
+	}
+	password := *f.Password
+	if password == "" {
+		password = utils.GenerateSecurePassword()
+	}
+	return &mariadb.Config{
+		Version:       *f.Version,
+		Port:          *f.Port,
+		Password:      password,
+		RootPassword:  *f.RootPassword,
+		ContainerName: *f.Name,
+		Username:      *f.User,
+		Database:      database,
+		Volume:        *f.Volume,
+		Memory:        *f.Memory,
+		CPU:           *f.CPU,
+		Timezone:      *f.Timezone,
+	}
+}
@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"flag"
+
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/databases/mysql"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// MySQLFlags holds all flag sets for MySQL operations
+type MySQLFlags struct {
+	CreateFlags  *flag.FlagSet
+	CustomFlags  *flag.FlagSet
+	Version      *string
+	Port         *string
+	Password     *string
+	RootPassword *string
+	User         *string
+	DBName       *string
+	Volume       *string
+	Memory       *string
+	CPU          *string
+	Name         *string
+	Timezone     *string
+}
+
+// NewMySQLFlags initializes all MySQL-related flags
+func NewMySQLFlags() *MySQLFlags {
+	f := &MySQLFlags{
+		CreateFlags: flag.NewFlagSet("create", flag.ExitOnError),
+		CustomFlags: flag.NewFlagSet("create-custom", flag.ExitOnError),
+	}
+
+	// Initialize create-custom flags
+	f.Version = f.CustomFlags.String("version", databases.MySQL.DefaultVersion, "MySQL version")
+	f.Port = f.CustomFlags.String("port", databases.MySQL.DefaultPortString(), "Port to expose")
+	f.Password = f.CustomFlags.String("password", "", "Password of --user (default: generated)")
+	f.RootPassword = f.CustomFlags.String("root-password", "", "Root password (default: --password)")
+	f.User = f.CustomFlags.String("user", "root", "Database user; any user other than root is created with access to --db")
+	f.DBName = f.CustomFlags.String("db", "", "Database name (default: --name)")
+	f.Volume = f.CustomFlags.String("volume", "", "Data volume path")
+	f.Memory = f.CustomFlags.String("memory", "", "Memory limit")
+	f.CPU = f.CustomFlags.String("cpu", "", "CPU limit")
+	f.Name = f.CustomFlags.String("name", "", "Container name")
+	f.Timezone = f.CustomFlags.String("timezone", "UTC", "Container timezone")
+
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags} {
+		addRuntimeFlags(fs)
+	}
+
+	setUsage(f.CreateFlags, commandHelp{
+		usage:       "create mysql <name>",
+		description: "Create a MySQL container with default settings and a generated root password.",
+		examples:    []string{"create mysql mydb"},
+	})
+	setUsage(f.CustomFlags, commandHelp{
+		usage:       "create-custom mysql --name <name> [flags]",
+		description: "Create a MySQL container with custom settings.",
+		examples: []string{
+			"create-custom mysql --name mydb --version 8.4 --port 3307",
+			"create-custom mysql --name mydb --user app --password secret --volume mydb-data",
+		},
+	})
+	return f
+}
+
+// BuildConfig creates a MySQL configuration from the flags
+func (f *MySQLFlags) BuildConfig() *mysql.Config {
+	database := *f.DBName
+	if database == "" {
+		database = *f.Name
+	}
+	password := *f.Password
+	if password == "" {
+		password = utils.GenerateSecurePassword()
+	}
+	return &mysql.Config{
+		Version:       *f.Version,
+		Port:          *f.Port,
+		Password:      password,
+		RootPassword:  *f.RootPassword,
+		ContainerName: *f.Name,
+		Username:      *f.User,
+		Database:      database,
+		Volume:        *f.Volume,
+		Memory:        *f.Memory,
+		CPU:           *f.CPU,
+		Timezone:      *f.Timezone,
+	}
+}
@@ -2,77 +2,498 @@ package flags
 
 import (
 	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/awade12/go-db/src/databases"
 	"github.com/awade12/go-db/src/databases/postgres"
+	"github.com/awade12/go-db/src/utils"
 )
 
 // PostgresFlags holds all flag sets for PostgreSQL operations
 type PostgresFlags struct {
-	CustomFlags   *flag.FlagSet
-	RemoveFlags   *flag.FlagSet
-	ListFlags     *flag.FlagSet
-	ShowFlags     *flag.FlagSet
-	Version       *string
-	Port          *string
-	Password      *string
-	User          *string
-	DBName        *string
-	Volume        *string
-	Memory        *string
-	CPU           *string
-	Name          *string
-	Timezone      *string
-	Locale        *string
-	Networks      *string
-	InitScripts   *string
-	SSLMode       *string
-	SSLCert       *string
-	SSLKey        *string
-	SSLRootCert   *string
-	ForceRemove   *bool
-	ShowContainer *string
+	CreateFlags            *flag.FlagSet
+	CustomFlags            *flag.FlagSet
+	StartFlags             *flag.FlagSet
+	StopFlags              *flag.FlagSet
+	RemoveFlags            *flag.FlagSet
+	BackupFlags            *flag.FlagSet
+	RestoreFlags           *flag.FlagSet
+	MaintenanceFlags       *flag.FlagSet
+	ListFlags              *flag.FlagSet
+	ShowFlags              *flag.FlagSet
+	LogsFlags              *flag.FlagSet
+	BenchFlags             *flag.FlagSet
+	ConnectFlags           *flag.FlagSet
+	PruneFlags             *flag.FlagSet
+	CatalogFlags           *flag.FlagSet
+	InspectFlags           *flag.FlagSet
+	MetricsFlags           *flag.FlagSet
+	RollbackFlags          *flag.FlagSet
+	AutostopFlags          *flag.FlagSet
+	RenameFlags            *flag.FlagSet
+	HealthFlags            *flag.FlagSet
+	CloneFlags             *flag.FlagSet
+	UpgradeFlags           *flag.FlagSet
+	Version                *string
+	Port                   *string
+	Password               *string
+	PasswordFile           *string
+	EnvFile                *string
+	WaitTimeout            *time.Duration
+	PreferIPv6             *bool
+	Force                  *bool
+	User                   *string
+	DBName                 *string
+	Volume                 *string
+	VolumeDriver           *string
+	RequireEncryptedVolume *bool
+	Memory                 *string
+	MemorySwap             *string
+	ShmSize                *string
+	CPU                    *string
+	StopSignal             *string
+	RestartPolicy          *string
+	Name                   *string
+	Timezone               *string
+	Locale                 *string
+	Networks               *string
+	NetworkCreate          *bool
+	InitScripts            *string
+	InitOrder              *string
+	StartupScripts         *string
+	SSLMode                *string
+	SSLCert                *string
+	SSLKey                 *string
+	SSLRootCert            *string
+	SSLGen                 *bool
+	PortRange              *string
+	ExactPort              *bool
+	ReadyCommand           *string
+	PreCreateHook          *string
+	PostCreateHook         *string
+	JSONLogs               *bool
+	ReuseExisting          *bool
+	Reconcile              *bool
+	ForceRecreate          *bool
+	CopyFrom               *string
+	WithPgBouncer          *bool
+	PoolMode               *string
+	SkipPull               *bool
+	Image                  *string
+	RegistryAuth           *string
+	HealthInterval         *string
+	HealthTimeout          *string
+	HealthRetries          *int
+	HealthStartPeriod      *string
+	WaitForHealthy         *bool
+	ConnectionTimeout      *string
+	ReplicaOf              *string
+	ReplicationUser        *string
+	ReplicationPassword    *string
+	LogQueries             *bool
+	PgParams               *paramMap
+	Labels                 *paramMap
+	MaxWALSize             *string
+	CheckpointTimeout      *string
+	MemAuto                *bool
+	MemFraction            *float64
+	TestMode               *bool
+	NoFsync                *bool
+	ConfigFile             *string
+	ConfigJSON             *string
+	PrintConfig            *bool
+	NoDefaultDB            *bool
+	NoNameValidation       *bool
+	ForceRemove            *bool
+	RemoveVolume           *bool
+	CleanupVolumes         *bool
+	Selector               *string
+	Quiet                  *bool
+	Prefix                 *string
+	OutputDir              *string
+	BackupOutput           *string
+	BackupFormat           *string
+	BackupChecksum         *bool
+	BackupJobs             *int
+	BackupSchemaOnly       *bool
+	BackupDataOnly         *bool
+	BackupGlobals          *bool
+	BackupCompress         *bool
+	BackupCompressLevel    *int
+	BackupFast             *bool
+	Schemas                *stringList
+	RestoreInput           *string
+	RestoreChecksum        *string
+	RestoreJobs            *int
+	RestoreNoOwner         *bool
+	RestoreNoACL           *bool
+	RestoreReportSize      *bool
+	RestoreNoGlobals       *bool
+	ListColumns            *string
+	SinceLastBackup        *bool
+	DetachKeys             *string
+	ConnectCommand         *string
+	BenchClients           *int
+	BenchJobs              *int
+	BenchTime              *int
+	BenchScale             *int
+	LogsLocalTime          *bool
+	LogsFollow             *bool
+	LogsTail               *int
+	AutostopIdle           *time.Duration
+	AutostopInterval       *time.Duration
+	HealthCheckTimeout     *time.Duration
+	UpgradeVersion         *string
+	UpgradeBackupDir       *string
+	UpgradeYes             *bool
+	RemoveYes              *bool
+	PruneYes               *bool
+	MetricsJSON            *bool
+	ShowContainer          *string
+	ShowRedact             *bool
+	ShowJSON               *bool
+	InspectRaw             *bool
+	Vacuum                 *bool
+	VacuumFull             *bool
+	Analyze                *bool
+	Reindex                *bool
 }
 
 // NewPostgresFlags initializes all PostgreSQL-related flags
 func NewPostgresFlags() *PostgresFlags {
 	f := &PostgresFlags{
-		CustomFlags: flag.NewFlagSet("create-custom", flag.ExitOnError),
-		RemoveFlags: flag.NewFlagSet("remove", flag.ExitOnError),
-		ListFlags:   flag.NewFlagSet("list", flag.ExitOnError),
-		ShowFlags:   flag.NewFlagSet("show", flag.ExitOnError),
+		CreateFlags:      flag.NewFlagSet("create", flag.ExitOnError),
+		CustomFlags:      flag.NewFlagSet("create-custom", flag.ExitOnError),
+		StartFlags:       flag.NewFlagSet("start", flag.ExitOnError),
+		StopFlags:        flag.NewFlagSet("stop", flag.ExitOnError),
+		RemoveFlags:      flag.NewFlagSet("remove", flag.ExitOnError),
+		BackupFlags:      flag.NewFlagSet("backup", flag.ExitOnError),
+		RestoreFlags:     flag.NewFlagSet("restore", flag.ExitOnError),
+		MaintenanceFlags: flag.NewFlagSet("maintenance", flag.ExitOnError),
+		ListFlags:        flag.NewFlagSet("list", flag.ExitOnError),
+		ShowFlags:        flag.NewFlagSet("show", flag.ExitOnError),
+		LogsFlags:        flag.NewFlagSet("logs", flag.ExitOnError),
+		BenchFlags:       flag.NewFlagSet("bench", flag.ExitOnError),
+		ConnectFlags:     flag.NewFlagSet("connect", flag.ExitOnError),
+		PruneFlags:       flag.NewFlagSet("prune", flag.ExitOnError),
+		CatalogFlags:     flag.NewFlagSet("tables", flag.ExitOnError),
+		InspectFlags:     flag.NewFlagSet("inspect", flag.ExitOnError),
+		MetricsFlags:     flag.NewFlagSet("metrics", flag.ExitOnError),
+		RollbackFlags:    flag.NewFlagSet("rollback", flag.ExitOnError),
+		AutostopFlags:    flag.NewFlagSet("autostop", flag.ExitOnError),
+		RenameFlags:      flag.NewFlagSet("rename", flag.ExitOnError),
+		HealthFlags:      flag.NewFlagSet("health", flag.ExitOnError),
+		CloneFlags:       flag.NewFlagSet("clone", flag.ExitOnError),
+		UpgradeFlags:     flag.NewFlagSet("upgrade", flag.ExitOnError),
 	}
 
 	// Initialize create-custom flags
-	f.Version = f.CustomFlags.String("version", "15", "PostgreSQL version")
-	f.Port = f.CustomFlags.String("port", "5432", "Port to expose")
-	f.Password = f.CustomFlags.String("password", "postgres", "Database password")
+	f.Version = f.CustomFlags.String("version", databases.Postgres.DefaultVersion, "PostgreSQL version")
+	f.Port = f.CustomFlags.String("port", databases.Postgres.DefaultPortString(), "Port to expose")
+	f.Password = f.CustomFlags.String("password", "", "Database password (default: generated; prefer --password-file or $GODB_PASSWORD)")
+	f.PasswordFile = f.CustomFlags.String("password-file", "", "Read the database password from this file (trailing newlines are trimmed)")
 	f.User = f.CustomFlags.String("user", "postgres", "Database user")
 	f.DBName = f.CustomFlags.String("db", "postgres", "Database name")
-	f.Volume = f.CustomFlags.String("volume", "", "Data volume path")
+	f.Volume = f.CustomFlags.String("volume", "", "Data volume: a host path (bind mount) or a docker volume name")
+	f.VolumeDriver = f.CustomFlags.String("volume-driver", "", "Driver to create a new named --volume with (e.g. an NFS plugin)")
+	f.RequireEncryptedVolume = f.CustomFlags.Bool("require-encrypted-volume", false, "Refuse to create unless the volume's host storage is LUKS/dm-crypt encrypted (Linux; warns elsewhere)")
 	f.Memory = f.CustomFlags.String("memory", "", "Memory limit")
+	f.MemorySwap = f.CustomFlags.String("memory-swap", "", "Memory plus swap limit, e.g. 2g, or -1 for unlimited swap (needs --memory)")
+	f.ShmSize = f.CustomFlags.String("shm-size", postgres.DefaultShmSize, "Size of /dev/shm; docker's 64m default breaks parallel query workers")
 	f.CPU = f.CustomFlags.String("cpu", "", "CPU limit")
 	f.Name = f.CustomFlags.String("name", "go-dbs-postgres", "Container name")
+	f.RestartPolicy = f.CustomFlags.String("restart", "no", "Docker restart policy: no, on-failure, always or unless-stopped (survives docker daemon restarts)")
+	f.StopSignal = f.CustomFlags.String("stop-signal", "", "Signal docker stop sends: SIGTERM (smart), SIGINT (fast) or SIGQUIT (immediate shutdown) (default: the image's, SIGINT)")
 	f.Timezone = f.CustomFlags.String("timezone", "UTC", "Container timezone")
 	f.Locale = f.CustomFlags.String("locale", "en_US.utf8", "Database locale")
 	f.Networks = f.CustomFlags.String("network", "", "Docker networks to join (comma-separated)")
+	f.NetworkCreate = f.CustomFlags.Bool("network-create", false, "Create the --network networks that don't exist yet (bridge driver)")
 	f.InitScripts = f.CustomFlags.String("init-script", "", "SQL scripts to run on initialization (comma-separated)")
+	f.InitOrder = f.CustomFlags.String("init-order", "", "Init script file names in execution order (comma-separated)")
+	f.StartupScripts = f.CustomFlags.String("startup-script", "", "SQL scripts to run after every create or start (comma-separated)")
 	f.SSLMode = f.CustomFlags.String("ssl-mode", "disable", "SSL mode")
 	f.SSLCert = f.CustomFlags.String("ssl-cert", "", "SSL certificate path")
 	f.SSLKey = f.CustomFlags.String("ssl-key", "", "SSL private key path")
 	f.SSLRootCert = f.CustomFlags.String("ssl-root-cert", "", "SSL root certificate path")
+	f.SSLGen = f.CustomFlags.Bool("ssl-gen", false, "Generate a self-signed certificate and enable SSL (implies --ssl-mode require)")
+	f.PortRange = f.CustomFlags.String("port-range", "", "Port range to allocate from (e.g., 6000-6100), or how many ports from --port to try (default 100)")
+	f.ExactPort = f.CustomFlags.Bool("exact-port", false, "Fail if --port is taken instead of trying the next ones")
+	f.ReadyCommand = f.CustomFlags.String("ready-cmd", "", "Readiness command to run inside the container (default: pg_isready)")
+	f.PreCreateHook = f.CustomFlags.String("pre-create-hook", "", "Local shell command to run before creating the container")
+	f.PostCreateHook = f.CustomFlags.String("post-create-hook", "", "Local shell command to run after the container is ready")
+	f.JSONLogs = f.CustomFlags.Bool("json-logs", false, "Emit structured JSON events to stderr")
+	f.ReuseExisting = f.CustomFlags.Bool("reuse-existing", false, "Reuse an existing container with the same name instead of failing")
+	f.Reconcile = f.CustomFlags.Bool("reconcile", false, "Compare an existing container with the requested configuration and report drift")
+	f.ForceRecreate = f.CustomFlags.Bool("force-recreate-on-config-change", false, "With --reconcile, recreate a container whose configuration has drifted")
+	f.CopyFrom = f.CustomFlags.String("copy-from", "", "Existing container whose data volume is cloned into the new database")
+	f.WithPgBouncer = f.CustomFlags.Bool("with-pgbouncer", false, "Start a PgBouncer connection pool in front of the database")
+	f.PoolMode = f.CustomFlags.String("pool-mode", "transaction", "PgBouncer pool mode (transaction, session)")
+	f.SkipPull = f.CustomFlags.Bool("skip-pull", false, "Skip the image pull step and use the local image only")
+	f.Image = f.CustomFlags.String("image", "postgres", "Image repository to run, e.g. registry.example.com/team/postgres (tagged with --version)")
+	f.RegistryAuth = f.CustomFlags.String("registry-auth", "", "user:password for the image's registry; logs in before pulling (or use docker login)")
+	f.HealthInterval = f.CustomFlags.String("health-interval", "5s", "Time between docker health checks")
+	f.HealthTimeout = f.CustomFlags.String("health-timeout", "5s", "Time before a single health check fails")
+	f.HealthRetries = f.CustomFlags.Int("health-retries", 5, "Consecutive failed checks before the container is unhealthy")
+	f.HealthStartPeriod = f.CustomFlags.String("health-start-period", "10s", "Startup grace period before failed checks count")
+	f.WaitForHealthy = f.CustomFlags.Bool("wait-for-healthy", false, "Wait until docker reports the container healthy instead of probing with pg_isready")
+	f.ConnectionTimeout = f.CustomFlags.String("connection-timeout", "5s", "Deadline for each readiness probe attempt against the server")
+	f.ReplicaOf = f.CustomFlags.String("replica-of", "", "Create a streaming standby of an external primary (host:port) via pg_basebackup")
+	f.ReplicationUser = f.CustomFlags.String("replication-user", "", "Role with REPLICATION to connect to the primary as (requires --replica-of)")
+	f.ReplicationPassword = f.CustomFlags.String("replication-password", "", "Password of the replication role")
+	f.LogQueries = f.CustomFlags.Bool("log-queries", false, "Log every statement to docker logs (development only)")
+	f.PgParams = &paramMap{}
+	f.CustomFlags.Var(f.PgParams, "pg-param", "postgresql.conf parameter as key=value, e.g. work_mem=16MB (can be repeated)")
+	f.Labels = &paramMap{}
+	f.CustomFlags.Var(f.Labels, "label", "Docker label as key=value, e.g. project=shop (can be repeated)")
+	f.MaxWALSize = f.CustomFlags.String("max-wal-size", "", "max_wal_size server setting, e.g. 2GB (fewer checkpoints for write-heavy loads)")
+	f.CheckpointTimeout = f.CustomFlags.String("checkpoint-timeout", "", "checkpoint_timeout server setting, e.g. 15min (30s to 1d)")
+	f.MemAuto = f.CustomFlags.Bool("mem-auto", false, "Set the memory limit to a share of the host's RAM and shared_buffers to a quarter of it")
+	f.MemFraction = f.CustomFlags.Float64("mem-fraction", 0.25, "Share of the host's RAM used by --mem-auto")
+	f.NoFsync = f.CustomFlags.Bool("no-fsync", false, "Turn off fsync, full_page_writes and synchronous_commit for fast throwaway databases (data loss on crash)")
+	f.TestMode = f.CustomFlags.Bool("test", false, "Disposable test database (random high port unless --port is given, tmpfs, trust auth, removed on stop, 15s --wait-timeout); prints only the connection string")
+	f.ConfigFile = f.CustomFlags.String("config", "", "YAML, TOML or JSON file with the configuration (keys are the flag names); explicitly set flags override its values")
+	f.ConfigJSON = f.CustomFlags.String("config-json", "", "JSON file with the configuration (same as --config)")
+	f.PrintConfig = f.CustomFlags.Bool("print-config", false, "Print the resolved configuration as JSON (password redacted) and exit")
+	f.NoDefaultDB = f.CustomFlags.Bool("no-default-db", false, "Create no extra database; use the built-in postgres database")
+	f.NoNameValidation = f.CustomFlags.Bool("no-name-validation", false, "Skip checking --name against docker's container naming rules")
+
+	// Initialize output flags shared by the create commands
+	f.Quiet = new(bool)
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags} {
+		fs.BoolVar(f.Quiet, "quiet", false, "Print only a single NDJSON result line on stdout")
+	}
+
+	// Initialize the namespace prefix shared by the create and management commands
+	f.Prefix = new(string)
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags, f.StartFlags, f.StopFlags, f.RemoveFlags, f.ListFlags, f.ShowFlags, f.CatalogFlags, f.InspectFlags, f.MetricsFlags, f.ConnectFlags, f.LogsFlags, f.RollbackFlags, f.AutostopFlags, f.RenameFlags, f.HealthFlags, f.CloneFlags, f.UpgradeFlags} {
+		fs.StringVar(f.Prefix, "prefix", os.Getenv("GODB_PREFIX"), "Container name namespace, e.g. team-a- (default: $GODB_PREFIX)")
+	}
+
+	// Initialize the .env output and readiness wait shared by the create commands
+	f.EnvFile, f.Force, f.WaitTimeout = new(string), new(bool), new(time.Duration)
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags} {
+		fs.DurationVar(f.WaitTimeout, "wait-timeout", 30*time.Second, "How long to wait for the server to accept connections before giving up")
+		fs.StringVar(f.EnvFile, "env-file", "", "Write PGHOST, PGPORT, PGUSER, PGPASSWORD, PGDATABASE and DATABASE_URL to this .env file")
+		fs.BoolVar(f.Force, "force", false, "Replace the variables --env-file already defines instead of refusing")
+	}
+
+	// Initialize the address choice shared by the commands that print connection strings
+	f.PreferIPv6 = new(bool)
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags, f.ShowFlags} {
+		fs.BoolVar(f.PreferIPv6, "prefer-ipv6", false, "Use the host's IPv6 address in the main connection string on dual-stack hosts")
+	}
+
+	// Initialize the artifact directory shared by the commands that write files
+	f.OutputDir = new(string)
+	for _, fs := range []*flag.FlagSet{f.CustomFlags, f.BackupFlags} {
+		fs.StringVar(f.OutputDir, "output-dir", "", "Directory for generated files (default: output_dir in ~/.go-db/config.json, else .)")
+	}
+
+	// Initialize the container runtime selection shared by every command
+	for _, fs := range []*flag.FlagSet{f.CreateFlags, f.CustomFlags, f.StartFlags, f.StopFlags, f.RemoveFlags, f.BackupFlags, f.RestoreFlags,
+		f.MaintenanceFlags, f.ListFlags, f.ShowFlags, f.LogsFlags, f.BenchFlags, f.ConnectFlags, f.PruneFlags, f.CatalogFlags, f.InspectFlags, f.MetricsFlags,
+		f.RollbackFlags, f.AutostopFlags, f.RenameFlags, f.HealthFlags, f.CloneFlags, f.UpgradeFlags} {
+		addRuntimeFlags(fs)
+	}
 
 	// Initialize remove flags
 	f.ForceRemove = f.RemoveFlags.Bool("force", false, "Force container removal")
+	f.RemoveVolume = f.RemoveFlags.Bool("volume", false, "Also remove the data volume if go-db created it")
+	f.RemoveYes = f.RemoveFlags.Bool("yes", false, "Remove without asking for confirmation (required when stdin is not a terminal)")
+
+	// Initialize prune flags
+	f.CleanupVolumes = f.PruneFlags.Bool("cleanup-volumes", false, "Remove dangling volumes created by go-db")
+	f.PruneYes = f.PruneFlags.Bool("yes", false, "Remove the volumes without asking for confirmation (required when stdin is not a terminal)")
+
+	// Initialize backup and restore flags
+	f.BackupOutput = f.BackupFlags.String("output", "", "Backup file path (default: <name>-<timestamp>.sql)")
+	f.BackupFormat = f.BackupFlags.String("format", "plain", "Dump format (plain, custom, directory)")
+	f.BackupChecksum = f.BackupFlags.Bool("checksum", false, "Write a .sha256 checksum file next to the backup")
+	f.BackupJobs = f.BackupFlags.Int("jobs", 0, "Parallel dump jobs (requires --format directory)")
+	f.BackupSchemaOnly = f.BackupFlags.Bool("schema-only", false, "Dump only the schema, no data")
+	f.BackupDataOnly = f.BackupFlags.Bool("data-only", false, "Dump only the data, no schema")
+	f.BackupGlobals = f.BackupFlags.Bool("globals", false, "Also dump roles and tablespaces (pg_dumpall --globals-only) to <dump>-globals.sql")
+	f.BackupCompress = f.BackupFlags.Bool("compress", false, "Gzip the dump while writing it (plain format, default file <name>-<timestamp>.sql.gz)")
+	f.BackupCompressLevel = f.BackupFlags.Int("compress-level", 6, "Gzip level for --compress, 1 (fastest) to 9 (smallest)")
+	f.BackupFast = f.BackupFlags.Bool("fast", false, "Favor speed: --compress uses level 1 for databases over 1 GB")
+	f.RestoreInput = f.RestoreFlags.String("input", "", "Dump file to restore")
+	f.RestoreChecksum = f.RestoreFlags.String("checksum", "", "Expected dump checksum (sha256:<hex>); restore aborts on mismatch")
+	f.RestoreJobs = f.RestoreFlags.Int("jobs", 0, "Parallel restore jobs (custom or directory format dumps)")
+	f.RestoreNoOwner = f.RestoreFlags.Bool("no-owner", false, "Skip ownership statements (restore under a different role)")
+	f.RestoreNoACL = f.RestoreFlags.Bool("no-acl", false, "Skip GRANT/REVOKE privilege statements")
+	f.RestoreReportSize = f.RestoreFlags.Bool("report-size", false, "Print the database size after restoring")
+	f.RestoreNoGlobals = f.RestoreFlags.Bool("no-globals", false, "Don't apply <dump>-globals.sql before the dump even if it exists")
+
+	// Initialize the schema filter shared by backup and restore
+	f.Schemas = new(stringList)
+	for _, fs := range []*flag.FlagSet{f.BackupFlags, f.RestoreFlags} {
+		fs.Var(f.Schemas, "schema", "Only include this schema (can be repeated or comma-separated)")
+	}
+
+	// Initialize maintenance flags
+	f.Vacuum = f.MaintenanceFlags.Bool("vacuum", false, "Run VACUUM")
+	f.VacuumFull = f.MaintenanceFlags.Bool("vacuum-full", false, "Run VACUUM FULL (locks tables while rewriting them)")
+	f.Analyze = f.MaintenanceFlags.Bool("analyze", false, "Run ANALYZE")
+	f.Reindex = f.MaintenanceFlags.Bool("reindex", false, "Run REINDEX DATABASE")
+
+	// Initialize label selector flags shared by the management commands
+	f.Selector = new(string)
+	for _, fs := range []*flag.FlagSet{f.StartFlags, f.StopFlags, f.RemoveFlags, f.ListFlags, f.AutostopFlags} {
+		fs.StringVar(f.Selector, "selector", "", "Act on all containers with a matching label (key=value)")
+	}
+
+	// Initialize list flags
+	f.ListColumns = f.ListFlags.String("columns", "", "Columns to display, comma-separated (name, type, status, uptime, port, id, backup)")
+	f.SinceLastBackup = f.ListFlags.Bool("since-last-backup", false, "Show when each database was last backed up and warn about overdue ones")
+
+	// Initialize connect and exec flags
+	f.DetachKeys = f.ConnectFlags.String("detach-keys", "", "Key sequence for detaching from the session (docker exec --detach-keys)")
+	f.ConnectCommand = f.ConnectFlags.String("command", "", "Run this SQL with psql -c instead of opening a session (connect only)")
+
+	// Initialize bench flags
+	f.BenchClients = f.BenchFlags.Int("clients", 10, "Number of concurrent pgbench clients")
+	f.BenchJobs = f.BenchFlags.Int("jobs", 0, "Number of pgbench threads (default: one per client, at most 4)")
+	f.BenchTime = f.BenchFlags.Int("time", 30, "Benchmark duration in seconds")
+	f.BenchScale = f.BenchFlags.Int("scale", 10, "pgbench scale factor used to initialize the tables")
+
+	// Initialize logs flags
+	f.LogsLocalTime = f.LogsFlags.Bool("local-time", false, "Prefix each line with its timestamp in the host's timezone")
+	f.LogsFollow = f.LogsFlags.Bool("follow", false, "Keep streaming new log lines until interrupted with Ctrl-C")
+	f.LogsTail = f.LogsFlags.Int("tail", 100, "Number of lines to show from the end of the logs (-1 for all)")
+
+	// Initialize autostop flags
+	f.AutostopIdle = f.AutostopFlags.Duration("idle", 30*time.Minute, "Stop a container after this long without client connections")
+	f.AutostopInterval = f.AutostopFlags.Duration("interval", time.Minute, "How often to check for connections")
+
+	// Initialize health flags
+	f.HealthCheckTimeout = f.HealthFlags.Duration("timeout", 0, "Keep checking until the container is healthy or this much time has passed (e.g. 30s)")
+
+	// Initialize upgrade flags
+	f.UpgradeVersion = f.UpgradeFlags.String("version", "", "PostgreSQL version (image tag) to upgrade to")
+	f.UpgradeBackupDir = f.UpgradeFlags.String("backup-dir", "", "Keep a pg_dumpall safety dump of all databases in this directory")
+	f.UpgradeYes = f.UpgradeFlags.Bool("yes", false, "Upgrade without asking for confirmation")
+
+	// Initialize metrics flags
+	f.MetricsJSON = f.MetricsFlags.Bool("json", false, "Print the metrics as JSON instead of the Prometheus text format")
 
 	// Initialize show flags
 	f.ShowContainer = f.ShowFlags.String("container", "", "Container name to show details for")
+	f.ShowRedact = f.ShowFlags.Bool("redact", false, "Replace the password with <redacted> in the output")
+	f.ShowJSON = f.ShowFlags.Bool("json", false, "Print the connection details as a JSON object without colors or decoration")
+
+	f.InspectRaw = f.InspectFlags.Bool("raw", false, "Print docker inspect's own output unchanged (passwords are not masked)")
 
+	f.setUsages()
 	return f
 }
 
+// stringList is a flag that can be repeated; each value may also hold a
+// comma-separated list
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, strings.Split(value, ",")...)
+	return nil
+}
+
+// paramMap is a repeatable key=value flag. Values are kept whole, since
+// settings such as search_path contain commas.
+type paramMap map[string]string
+
+func (m *paramMap) String() string {
+	if m == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*m))
+	for key, value := range *m {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m *paramMap) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return fmt.Errorf("expected key=value")
+	}
+	if *m == nil {
+		*m = make(paramMap)
+	}
+	(*m)[strings.TrimSpace(key)] = val
+	return nil
+}
+
+// envName returns the environment variable that provides the default for a
+// flag, e.g. --health-retries maps to GODB_HEALTH_RETRIES
+func envName(flagName string) string {
+	return "GODB_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// addRuntimeFlags adds the flags that control how container commands are run,
+// which every command accepts
+func addRuntimeFlags(fs *flag.FlagSet) {
+	fs.Func("runtime", "Container CLI to use: docker or podman (default: $GODB_RUNTIME, else runtime in ~/.go-db/config.json, else docker)", utils.SetRuntime)
+	fs.BoolFunc("verbose", "Print each docker command to stderr before running it", func(value string) error {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		utils.SetVerbose(on)
+		return nil
+	})
+	fs.BoolFunc("no-public-ip", "Don't look up the host's public IP; connection details show only the local address", func(value string) error {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		utils.SetNoPublicIP(on)
+		return nil
+	})
+}
+
+// applyEnvDefaults sets each flag in fs that was not given on the command
+// line from its GODB_<FLAG> environment variable, so explicit flags override
+// it. It only runs for create-custom, so a stale variable cannot break the
+// commands that don't read the flags.
+func applyEnvDefaults(fs *flag.FlagSet) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) {
+		explicit[fl.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(fl *flag.Flag) {
+		value, ok := os.LookupEnv(envName(fl.Name))
+		if err != nil || !ok || explicit[fl.Name] {
+			return
+		}
+		if setErr := fs.Set(fl.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %v", value, envName(fl.Name), setErr)
+		}
+	})
+	return err
+}
+
 // BuildConfig creates a PostgreSQL configuration from the flags
-func (f *PostgresFlags) BuildConfig() *postgres.Config {
+func (f *PostgresFlags) BuildConfig() (*postgres.Config, error) {
+	// Every create-custom flag falls back to a GODB_<FLAG> environment variable
+	if err := applyEnvDefaults(f.CustomFlags); err != nil {
+		return nil, err
+	}
+
 	var networkList []string
 	if *f.Networks != "" {
 		networkList = strings.Split(*f.Networks, ",")
@@ -83,23 +504,252 @@ func (f *PostgresFlags) BuildConfig() *postgres.Config {
 		scriptList = strings.Split(*f.InitScripts, ",")
 	}
 
-	return &postgres.Config{
-		Version:       *f.Version,
-		Port:          *f.Port,
-		Password:      *f.Password,
-		ContainerName: *f.Name,
-		Username:      *f.User,
-		Database:      *f.DBName,
-		Volume:        *f.Volume,
-		Memory:        *f.Memory,
-		CPU:           *f.CPU,
-		Networks:      networkList,
-		InitScripts:   scriptList,
-		Timezone:      *f.Timezone,
-		Locale:        *f.Locale,
-		SSLMode:       *f.SSLMode,
-		SSLCert:       *f.SSLCert,
-		SSLKey:        *f.SSLKey,
-		SSLRootCert:   *f.SSLRootCert,
+	var initOrder []string
+	if *f.InitOrder != "" {
+		initOrder = strings.Split(*f.InitOrder, ",")
+	}
+
+	var startupScripts []string
+	if *f.StartupScripts != "" {
+		startupScripts = strings.Split(*f.StartupScripts, ",")
+	}
+
+	cfg := &postgres.Config{
+		Version:                *f.Version,
+		Port:                   *f.Port,
+		Password:               *f.Password,
+		ContainerName:          *f.Name,
+		Username:               *f.User,
+		Database:               *f.DBName,
+		Volume:                 *f.Volume,
+		VolumeDriver:           *f.VolumeDriver,
+		RequireEncryptedVolume: *f.RequireEncryptedVolume,
+		Memory:                 *f.Memory,
+		MemorySwap:             *f.MemorySwap,
+		ShmSize:                *f.ShmSize,
+		CPU:                    *f.CPU,
+		StopSignal:             *f.StopSignal,
+		RestartPolicy:          *f.RestartPolicy,
+		Networks:               networkList,
+		NetworkCreate:          *f.NetworkCreate,
+		InitScripts:            scriptList,
+		InitOrder:              initOrder,
+		StartupScripts:         startupScripts,
+		Timezone:               *f.Timezone,
+		Locale:                 *f.Locale,
+		SSLMode:                *f.SSLMode,
+		SSLCert:                *f.SSLCert,
+		SSLKey:                 *f.SSLKey,
+		SSLRootCert:            *f.SSLRootCert,
+		SSLGen:                 *f.SSLGen,
+		OutputDir:              *f.OutputDir,
+		PortRange:              *f.PortRange,
+		ExactPort:              *f.ExactPort,
+		ReadyCommand:           *f.ReadyCommand,
+		PreCreateHook:          *f.PreCreateHook,
+		PostCreateHook:         *f.PostCreateHook,
+		JSONLogs:               *f.JSONLogs,
+		ReuseExisting:          *f.ReuseExisting,
+		Reconcile:              *f.Reconcile,
+		ForceRecreate:          *f.ForceRecreate,
+		CopyFrom:               *f.CopyFrom,
+		WithPgBouncer:          *f.WithPgBouncer,
+		PoolMode:               *f.PoolMode,
+		SkipPull:               *f.SkipPull,
+		Image:                  *f.Image,
+		RegistryAuth:           *f.RegistryAuth,
+		Prefix:                 *f.Prefix,
+		EnvFile:                *f.EnvFile,
+		ReadinessTimeout:       *f.WaitTimeout,
+		PreferIPv6:             *f.PreferIPv6,
+		Force:                  *f.Force,
+		HealthInterval:         *f.HealthInterval,
+		HealthTimeout:          *f.HealthTimeout,
+		HealthRetries:          *f.HealthRetries,
+		HealthStartPeriod:      *f.HealthStartPeriod,
+		WaitForHealthy:         *f.WaitForHealthy,
+		ConnectionTimeout:      *f.ConnectionTimeout,
+		ReplicaOf:              *f.ReplicaOf,
+		ReplicationUser:        *f.ReplicationUser,
+		ReplicationPassword:    *f.ReplicationPassword,
+		LogQueries:             *f.LogQueries,
+		PostgresParams:         *f.PgParams,
+		Labels:                 *f.Labels,
+		MaxWALSize:             *f.MaxWALSize,
+		CheckpointTimeout:      *f.CheckpointTimeout,
+		MemAuto:                *f.MemAuto,
+		MemFraction:            *f.MemFraction,
+		TestMode:               *f.TestMode,
+		NoFsync:                *f.NoFsync,
+		NoDefaultDB:            *f.NoDefaultDB,
+		NoNameValidation:       *f.NoNameValidation,
+	}
+
+	path := *f.ConfigFile
+	if path == "" {
+		path = *f.ConfigJSON
+	} else if *f.ConfigJSON != "" {
+		return nil, fmt.Errorf("--config and --config-json are mutually exclusive")
+	}
+	if path == "" {
+		return f.withPassword(cfg)
+	}
+
+	// Flag defaults < config file < explicitly set flags
+	var fileCfg postgres.Config
+	if err := copyConfig(cfg, &fileCfg); err != nil {
+		return nil, err
+	}
+	if err := loadConfigFile(path, &fileCfg); err != nil {
+		return nil, err
+	}
+	if err := overlaySetFlags(f.CustomFlags, cfg, &fileCfg); err != nil {
+		return nil, err
+	}
+	if fileCfg.ContainerName == "" {
+		return nil, fmt.Errorf("invalid config file %s: key \"name\" is required (or pass --name)", path)
+	}
+	if err := fileCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return f.withPassword(&fileCfg)
+}
+
+// withPassword settles the password: --password-file wins over
+// $GODB_PASSWORD, which wins over --password (or the config file's password);
+// without any of them a password is generated
+func (f *PostgresFlags) withPassword(cfg *postgres.Config) (*postgres.Config, error) {
+	switch {
+	case *f.PasswordFile != "":
+		password, err := readPasswordFile(*f.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Password = password
+	case os.Getenv("GODB_PASSWORD") != "":
+		cfg.Password = os.Getenv("GODB_PASSWORD")
+	case cfg.Password == "":
+		cfg.Password = utils.GenerateSecurePassword()
+		cfg.PasswordGenerated = true
+	}
+	return cfg, nil
+}
+
+// BuildBackupOptions creates backup options from the flags
+func (f *PostgresFlags) BuildBackupOptions() postgres.BackupOptions {
+	return postgres.BackupOptions{
+		Output:     *f.BackupOutput,
+		Format:     *f.BackupFormat,
+		Checksum:   *f.BackupChecksum,
+		Jobs:       *f.BackupJobs,
+		SchemaOnly: *f.BackupSchemaOnly,
+		DataOnly:   *f.BackupDataOnly,
+		OutputDir:  *f.OutputDir,
+		Globals:    *f.BackupGlobals,
+		Compress:   *f.BackupCompress,
+		Level:      *f.BackupCompressLevel,
+		Fast:       *f.BackupFast,
+		Schemas:    *f.Schemas,
+	}
+}
+
+// BuildRestoreOptions creates restore options from the flags
+func (f *PostgresFlags) BuildRestoreOptions() postgres.RestoreOptions {
+	return postgres.RestoreOptions{
+		Input:      *f.RestoreInput,
+		Checksum:   *f.RestoreChecksum,
+		Jobs:       *f.RestoreJobs,
+		NoOwner:    *f.RestoreNoOwner,
+		NoACL:      *f.RestoreNoACL,
+		ReportSize: *f.RestoreReportSize,
+		NoGlobals:  *f.RestoreNoGlobals,
+		Schemas:    *f.Schemas,
+	}
+}
+
+// BuildListOptions creates list options from the flags
+func (f *PostgresFlags) BuildListOptions() postgres.ListOptions {
+	var columns []string
+	if *f.ListColumns != "" {
+		columns = strings.Split(*f.ListColumns, ",")
+	}
+
+	return postgres.ListOptions{
+		Selector:        *f.Selector,
+		Prefix:          *f.Prefix,
+		Columns:         columns,
+		SinceLastBackup: *f.SinceLastBackup,
+	}
+}
+
+// BuildConnectOptions creates connect and exec options from the flags
+func (f *PostgresFlags) BuildConnectOptions() postgres.ConnectOptions {
+	return postgres.ConnectOptions{
+		DetachKeys: *f.DetachKeys,
+		Command:    *f.ConnectCommand,
+	}
+}
+
+// BuildBenchOptions creates bench options from the flags
+func (f *PostgresFlags) BuildBenchOptions() postgres.BenchOptions {
+	return postgres.BenchOptions{
+		Clients: *f.BenchClients,
+		Jobs:    *f.BenchJobs,
+		Time:    *f.BenchTime,
+		Scale:   *f.BenchScale,
+	}
+}
+
+// BuildLogsOptions creates logs options from the flags
+func (f *PostgresFlags) BuildLogsOptions() postgres.LogsOptions {
+	return postgres.LogsOptions{
+		LocalTime: *f.LogsLocalTime,
+		Follow:    *f.LogsFollow,
+		Tail:      *f.LogsTail,
+	}
+}
+
+// BuildAutostopOptions creates autostop options from the flags
+func (f *PostgresFlags) BuildAutostopOptions() postgres.AutostopOptions {
+	return postgres.AutostopOptions{
+		Idle:     *f.AutostopIdle,
+		Interval: *f.AutostopInterval,
+		Selector: *f.Selector,
+		Prefix:   *f.Prefix,
+	}
+}
+
+// BuildUpgradeOptions creates upgrade options from the flags
+func (f *PostgresFlags) BuildUpgradeOptions() postgres.UpgradeOptions {
+	return postgres.UpgradeOptions{
+		Version:   *f.UpgradeVersion,
+		BackupDir: *f.UpgradeBackupDir,
+		Yes:       *f.UpgradeYes,
+	}
+}
+
+// BuildMetricsOptions creates metrics options from the flags
+func (f *PostgresFlags) BuildMetricsOptions() postgres.MetricsOptions {
+	return postgres.MetricsOptions{
+		JSON: *f.MetricsJSON,
+	}
+}
+
+// BuildShowOptions creates show options from the flags
+func (f *PostgresFlags) BuildShowOptions() postgres.ShowOptions {
+	return postgres.ShowOptions{
+		Redact:     *f.ShowRedact,
+		JSON:       *f.ShowJSON,
+		PreferIPv6: *f.PreferIPv6,
+	}
+}
+
+// BuildMaintenanceOptions creates maintenance options from the flags
+func (f *PostgresFlags) BuildMaintenanceOptions() postgres.MaintenanceOptions {
+	return postgres.MaintenanceOptions{
+		Vacuum:     *f.Vacuum,
+		VacuumFull: *f.VacuumFull,
+		Analyze:    *f.Analyze,
+		Reindex:    *f.Reindex,
 	}
 }
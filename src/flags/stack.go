@@ -0,0 +1,81 @@
+package flags
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/awade12/go-db/src/stack"
+)
+
+// StackFlags holds the flag sets of the stack commands
+type StackFlags struct {
+	ApplyFlags    *flag.FlagSet
+	DownFlags     *flag.FlagSet
+	ValidateFlags *flag.FlagSet
+
+	// Down flags
+	Yes      *bool
+	Volumes  *bool
+	Networks *bool
+
+	// Validate flags
+	ConfigFile *string
+	ConfigJSON *string
+}
+
+// NewStackFlags initializes the flags of apply, down and validate
+func NewStackFlags() *StackFlags {
+	f := &StackFlags{
+		ApplyFlags: flag.NewFlagSet("apply", flag.ExitOnError),
+		DownFlags:  flag.NewFlagSet("down", flag.ExitOnError),
+
+		ValidateFlags: flag.NewFlagSet("validate", flag.ExitOnError),
+	}
+	for _, fs := range []*flag.FlagSet{f.ApplyFlags, f.DownFlags} {
+		addRuntimeFlags(fs)
+	}
+
+	f.Yes = f.DownFlags.Bool("yes", false, "Remove without asking for confirmation")
+	f.Volumes = f.DownFlags.Bool("volumes", false, "Also remove the data volumes go-db created")
+	f.Networks = f.DownFlags.Bool("networks", false, "Also remove the networks apply created")
+
+	f.ConfigFile = f.ValidateFlags.String("config", "", "Check a create-custom config file (YAML, TOML or JSON) instead of a stack file")
+	f.ConfigJSON = f.ValidateFlags.String("config-json", "", "Check a create-custom JSON config file (same as --config)")
+
+	setUsage(f.ApplyFlags, commandHelp{
+		usage:       "apply <stack-file> [flags]",
+		description: "Create every database defined in a YAML, TOML or JSON stack file, in dependency order, with the networks they share. Existing databases are left as they are.",
+		examples:    []string{"apply stack.yaml"},
+	})
+	setUsage(f.DownFlags, commandHelp{
+		usage:       "down <stack-file> [flags]",
+		description: "Remove the databases a previous apply created, in reverse dependency order, after asking for confirmation. Databases, volumes and networks that existed before are kept. Alias: destroy.",
+		examples:    []string{"down stack.yaml", "down stack.yaml --volumes --networks --yes"},
+	})
+	setUsage(f.ValidateFlags, commandHelp{
+		usage:       "validate <stack-file> | validate --config <file>",
+		description: "Check a stack file, or a create-custom config file, without touching docker: every entry is decoded and validated, and duplicate names, unknown dependencies and port collisions are reported. Exits non-zero if anything is wrong.",
+		examples:    []string{"validate stack.yaml", "validate --config-json db.json"},
+	})
+	return f
+}
+
+// ConfigPath returns the config file validate checks, if any
+func (f *StackFlags) ConfigPath() (string, error) {
+	if *f.ConfigFile != "" && *f.ConfigJSON != "" {
+		return "", fmt.Errorf("--config and --config-json are mutually exclusive")
+	}
+	if *f.ConfigFile != "" {
+		return *f.ConfigFile, nil
+	}
+	return *f.ConfigJSON, nil
+}
+
+// BuildDownOptions creates the down options from the parsed flags
+func (f *StackFlags) BuildDownOptions() stack.DownOptions {
+	return stack.DownOptions{
+		Yes:      *f.Yes,
+		Volumes:  *f.Volumes,
+		Networks: *f.Networks,
+	}
+}
@@ -0,0 +1,157 @@
+package flags
+
+import (
+	"flag"
+	"fmt"
+)
+
+// commandHelp describes a command for its --help output
+type commandHelp struct {
+	usage       string
+	description string
+	examples    []string
+}
+
+// setUsage makes fs print the command's usage, its flags and examples on --help
+func setUsage(fs *flag.FlagSet, help commandHelp) {
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: go-db %s\n\n%s\n", help.usage, help.description)
+
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(out, "\nFlags:")
+			fs.PrintDefaults()
+		}
+
+		if len(help.examples) > 0 {
+			fmt.Fprintln(out, "\nExamples:")
+			for _, example := range help.examples {
+				fmt.Fprintf(out, "  go-db %s\n", example)
+			}
+		}
+	}
+}
+
+// setUsages wires the per-command help of every flag set
+func (f *PostgresFlags) setUsages() {
+	setUsage(f.CreateFlags, commandHelp{
+		usage:       "create postgres <name> [flags]",
+		description: "Create a PostgreSQL container with default settings.",
+		examples:    []string{"create postgres mydb", "create postgres mydb --quiet | jq .connectionString"},
+	})
+	setUsage(f.CustomFlags, commandHelp{
+		usage:       "create-custom postgres --name <name> [flags]",
+		description: "Create a PostgreSQL container with custom settings. Every flag also reads a GODB_<FLAG> environment variable.",
+		examples: []string{
+			"create-custom postgres --name mydb --version 16 --port 5433",
+			"create-custom postgres --name mydb --memory 1g --cpu 0.5 --volume mydb-data",
+		},
+	})
+	setUsage(f.StartFlags, commandHelp{
+		usage:       "start <name> [flags]",
+		description: "Start a stopped database container and run its startup scripts.",
+		examples:    []string{"start mydb", "start --selector env=dev"},
+	})
+	setUsage(f.StopFlags, commandHelp{
+		usage:       "stop <name> [flags]",
+		description: "Stop a running database container.",
+		examples:    []string{"stop mydb", "stop --selector env=dev"},
+	})
+	setUsage(f.RemoveFlags, commandHelp{
+		usage:       "remove <name> [flags]",
+		description: "Remove a database container and its sidecars. Asks for confirmation first unless --yes is given; without a terminal, --yes is required.",
+		examples:    []string{"remove mydb", "remove mydb --force --volume", "remove --selector env=ci --yes"},
+	})
+	setUsage(f.ListFlags, commandHelp{
+		usage:       "list [flags]",
+		description: "List all PostgreSQL containers, running and stopped.",
+		examples:    []string{"list", "list --columns name,status,uptime", "list --prefix team-a-"},
+	})
+	setUsage(f.ShowFlags, commandHelp{
+		usage:       "show <name> [flags]",
+		description: "Show the connection details of a database container.",
+		examples:    []string{"show mydb", "show mydb --redact", "show mydb --json | jq -r .connectionString"},
+	})
+	setUsage(f.BackupFlags, commandHelp{
+		usage:       "backup <name> [flags]",
+		description: "Dump a database to a local file with pg_dump.",
+		examples:    []string{"backup mydb --output mydb.sql --checksum", "backup mydb --format directory --jobs 4", "backup mydb --output mydb.sql --globals"},
+	})
+	setUsage(f.RestoreFlags, commandHelp{
+		usage:       "restore <name> <file> [flags]",
+		description: "Load a plain, custom or directory format dump into a database.",
+		examples:    []string{"restore mydb mydb.sql", "restore mydb mydb.dump --jobs 4 --no-owner"},
+	})
+	setUsage(f.MaintenanceFlags, commandHelp{
+		usage:       "maintenance <name> [flags]",
+		description: "Run VACUUM, ANALYZE or REINDEX against a database.",
+		examples:    []string{"maintenance mydb --vacuum --analyze", "maintenance mydb --reindex"},
+	})
+	setUsage(f.CatalogFlags, commandHelp{
+		usage:       "tables|databases|users <name> [flags]",
+		description: "List the tables, databases or roles of a running database container.",
+		examples:    []string{"tables mydb", "databases mydb", "users mydb"},
+	})
+	setUsage(f.InspectFlags, commandHelp{
+		usage:       "inspect <name> [flags]",
+		description: "Print a container's image, environment (passwords masked), mounts, networks, port bindings, resource limits, restart policy and state as JSON.",
+		examples:    []string{"inspect mydb", "inspect mydb --raw"},
+	})
+	setUsage(f.MetricsFlags, commandHelp{
+		usage:       "metrics <name> [flags]",
+		description: "Print pg_stat_database, pg_stat_bgwriter and connection statistics in the Prometheus text format.",
+		examples:    []string{"metrics mydb", "metrics mydb > /var/lib/node_exporter/mydb.prom", "metrics mydb --json"},
+	})
+	setUsage(f.RollbackFlags, commandHelp{
+		usage:       "rollback <name> [flags]",
+		description: "Undo an upgrade: stop <name>, keep it as <name>.rolled-back and bring <name>.bak back as <name>.",
+		examples:    []string{"rollback mydb"},
+	})
+	setUsage(f.UpgradeFlags, commandHelp{
+		usage:       "upgrade <name> --version <version> [flags]",
+		description: "Move a running container to another PostgreSQL version. Within a major version it is recreated on the new image with the same data volume; across major versions all databases are dumped with pg_dumpall and restored into a fresh volume. The old container is kept as <name>.bak for rollback.",
+		examples:    []string{"upgrade mydb --version 15.6", "upgrade mydb --version 16 --backup-dir ./backups"},
+	})
+	setUsage(f.RenameFlags, commandHelp{
+		usage:       "rename <old> <new> [flags]",
+		description: "Rename a container. The database inside keeps its name (POSTGRES_DB); only the container name changes.",
+		examples:    []string{"rename pg1 orders-db"},
+	})
+	setUsage(f.CloneFlags, commandHelp{
+		usage:       "clone <source> <new-name> [flags]",
+		description: "Create a new container with the source's image, version, user, database and limits, and load a pg_dump of the running source into it. The dump is piped between the containers, never written to disk; the clone gets a new password.",
+		examples:    []string{"clone mydb mydb-fixture"},
+	})
+	setUsage(f.HealthFlags, commandHelp{
+		usage:       "health <name> [flags]",
+		description: "Check that a container is running, not failing its docker healthcheck and accepting connections (pg_isready). Exits non-zero when it is unhealthy, for monitoring scripts.",
+		examples:    []string{"health mydb", "health mydb --timeout 30s"},
+	})
+	setUsage(f.AutostopFlags, commandHelp{
+		usage:       "autostop [flags]",
+		description: "Watch the running PostgreSQL containers in the foreground and stop each one that had no client connections (per pg_stat_activity) for --idle. Stopped containers are not restarted; run go-db start when you need one again.",
+		examples:    []string{"autostop --idle 30m", "autostop --idle 2h --selector env=dev"},
+	})
+	setUsage(f.PruneFlags, commandHelp{
+		usage:       "prune [flags]",
+		description: "List dangling volumes created by go-db, or remove them with --cleanup-volumes (after confirmation, or right away with --yes).",
+		examples:    []string{"prune", "prune --cleanup-volumes", "prune --cleanup-volumes --yes"},
+	})
+	setUsage(f.ConnectFlags, commandHelp{
+		usage:       "connect|psql <name> [flags] | exec <name> [flags] -- <command>",
+		description: "Open an interactive psql session, or run a command, inside a database container.",
+		examples:    []string{"connect mydb", "psql mydb --command 'SELECT version()'", "connect mydb --detach-keys ctrl-x,x", "exec mydb -- bash"},
+	})
+	setUsage(f.LogsFlags, commandHelp{
+		usage:       "logs <name> [flags]",
+		description: "Print the last lines of a database container's logs, or follow them.",
+		examples:    []string{"logs mydb", "logs mydb --follow", "logs mydb --tail -1 --local-time"},
+	})
+	setUsage(f.BenchFlags, commandHelp{
+		usage:       "bench <name> [flags]",
+		description: "Initialize the pgbench tables and run a pgbench benchmark, printing the TPS.",
+		examples:    []string{"bench mydb", "bench mydb --clients 20 --time 60"},
+	})
+}
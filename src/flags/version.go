@@ -0,0 +1,27 @@
+package flags
+
+import (
+	"flag"
+)
+
+// VersionFlags holds the flags of the version command
+type VersionFlags struct {
+	VersionFlags *flag.FlagSet
+
+	JSON *bool
+}
+
+// NewVersionFlags initializes the flags of version
+func NewVersionFlags() *VersionFlags {
+	f := &VersionFlags{
+		VersionFlags: flag.NewFlagSet("version", flag.ExitOnError),
+	}
+	f.JSON = f.VersionFlags.Bool("json", false, "Print the version information as a JSON object")
+
+	setUsage(f.VersionFlags, commandHelp{
+		usage:       "version [flags]",
+		description: "Print the go-db version, the git commit it was built from and the build date. Include it when reporting a bug.",
+		examples:    []string{"version", "version --json"},
+	})
+	return f
+}
@@ -0,0 +1,279 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases/mysql"
+	"github.com/awade12/go-db/src/databases/postgres"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// Statuses reported in the summary table
+const (
+	statusCreated = "created"
+	statusExists  = "exists"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+	statusRemoved = "removed"
+	statusMissing = "not found"
+	statusKept    = "kept"
+)
+
+// result is one row of the summary table
+type result struct {
+	db     Database
+	status string
+}
+
+// Apply creates every database of a stack file that doesn't exist yet, in
+// dependency order, after creating the networks they share. Databases that
+// already exist are left untouched, so applying a stack again is safe. The
+// first failure stops the run, as later databases may depend on it.
+func Apply(path string) error {
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := utils.CheckRuntime(); err != nil {
+		return err
+	}
+
+	printf("%s Applying stack %s (%d databases)\n", info("ℹ"), s.Name, len(s.Databases))
+
+	// Record what this run creates, even if it fails halfway, so down can
+	// remove exactly that
+	var created utils.StackState
+	defer func() {
+		if len(created.Containers) == 0 && len(created.Networks) == 0 {
+			return
+		}
+		if err := recordCreated(s.Name, created); err != nil {
+			printf("%s Warning: Could not record the created resources: %v\n", warn("⚠"), err)
+		}
+	}()
+
+	for _, network := range s.networks() {
+		if exists("network", network) {
+			continue
+		}
+		output, err := utils.RunDocker("network", "create", "--label", fmt.Sprintf("%s=%s", stackLabel, s.Name), network).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("Failed to create network %s: %v: %s", network, err, strings.TrimSpace(string(output)))
+		}
+		printf("%s Created network %s\n", success("✔"), network)
+		created.Networks = append(created.Networks, network)
+	}
+
+	var results []result
+	var failure error
+	for _, db := range s.Databases {
+		if failure != nil {
+			results = append(results, result{db, statusSkipped})
+			continue
+		}
+		if exists("container", db.Name) {
+			printf("%s %s already exists, leaving it as is\n", info("ℹ"), db.Name)
+			results = append(results, result{db, statusExists})
+			continue
+		}
+
+		if db.postgres != nil {
+			err = postgres.CreateWithConfig(db.postgres)
+		} else {
+			err = mysql.CreateWithConfig(db.mysql)
+		}
+		if err != nil {
+			failure = fmt.Errorf("Failed to create %s: %v", db.Name, err)
+			results = append(results, result{db, statusFailed})
+			continue
+		}
+		results = append(results, result{db, statusCreated})
+		created.Containers = append(created.Containers, db.Name)
+	}
+
+	printSummary(results)
+	return failure
+}
+
+// DownOptions controls what Down removes besides the containers
+type DownOptions struct {
+	Volumes  bool // also remove the data volumes go-db created
+	Networks bool // also remove the networks apply created
+	Yes      bool // don't ask for confirmation
+}
+
+// Down removes the databases of a stack in reverse dependency order. Only
+// what apply created is removed: databases and networks that existed before
+// are kept, and so are volumes go-db didn't create.
+func Down(path string, opts DownOptions) error {
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := utils.CheckRuntime(); err != nil {
+		return err
+	}
+
+	state, err := utils.LoadState()
+	if err != nil {
+		return err
+	}
+	record, ok := state.Stacks[s.Name]
+	if !ok {
+		return fmt.Errorf("No record of applying stack %s, so nothing it created can be told apart; remove its databases with go-db remove", s.Name)
+	}
+
+	var networks []string
+	if opts.Networks {
+		networks = record.Networks
+	}
+	if !opts.Yes {
+		question := fmt.Sprintf("Remove %s", strings.Join(record.Containers, ", "))
+		if len(networks) > 0 {
+			question += fmt.Sprintf(" and networks %s", strings.Join(networks, ", "))
+		}
+		if opts.Volumes {
+			question += " with their data volumes"
+		}
+		ok, err := utils.Confirm(question + "?")
+		if err == utils.ErrNotInteractive {
+			return fmt.Errorf("refusing to remove stack %s without confirmation; pass --yes", s.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			printf("%s Nothing removed\n", info("ℹ"))
+			return nil
+		}
+	}
+
+	printf("%s Taking down stack %s\n", info("ℹ"), s.Name)
+	var results []result
+	var failure error
+	for i := len(s.Databases) - 1; i >= 0; i-- {
+		db := s.Databases[i]
+		switch {
+		case !exists("container", db.Name):
+			results = append(results, result{db, statusMissing})
+			record.Containers = without(record.Containers, db.Name)
+			continue
+		case !contains(record.Containers, db.Name):
+			printf("%s Keeping %s, it existed before the stack was applied\n", info("ℹ"), db.Name)
+			results = append(results, result{db, statusKept})
+			continue
+		}
+
+		remove := postgres.Remove
+		if db.mysql != nil {
+			remove = mysql.Remove
+			if opts.Volumes {
+				printf("%s Keeping the data volume of %s, volume removal is PostgreSQL-only\n", info("ℹ"), db.Name)
+			}
+		} else if opts.Volumes {
+			remove = postgres.RemoveWithVolume
+		}
+		if err := remove(db.Name, true); err != nil {
+			if failure == nil {
+				failure = fmt.Errorf("Failed to remove %s: %v", db.Name, err)
+			}
+			results = append(results, result{db, statusFailed})
+			continue
+		}
+		results = append(results, result{db, statusRemoved})
+		record.Containers = without(record.Containers, db.Name)
+	}
+
+	for _, network := range networks {
+		if failure != nil {
+			break
+		}
+		if output, err := utils.RunDocker("network", "rm", network).CombinedOutput(); err != nil && exists("network", network) {
+			printf("%s Warning: Could not remove network %s: %s\n", warn("⚠"), network, strings.TrimSpace(string(output)))
+			continue
+		}
+		printf("%s Removed network %s\n", success("✔"), network)
+		record.Networks = without(record.Networks, network)
+	}
+
+	if err := utils.UpdateState(func(state *utils.State) {
+		if len(record.Containers) == 0 && len(record.Networks) == 0 {
+			delete(state.Stacks, s.Name)
+		} else {
+			state.Stacks[s.Name] = record
+		}
+	}); err != nil {
+		printf("%s Warning: Could not update the state file: %v\n", warn("⚠"), err)
+	}
+
+	printSummary(results)
+	return failure
+}
+
+// recordCreated adds what an apply created to the stack's record
+func recordCreated(name string, created utils.StackState) error {
+	return utils.UpdateState(func(state *utils.State) {
+		record := state.Stacks[name]
+		for _, container := range created.Containers {
+			if !contains(record.Containers, container) {
+				record.Containers = append(record.Containers, container)
+			}
+		}
+		for _, network := range created.Networks {
+			if !contains(record.Networks, network) {
+				record.Networks = append(record.Networks, network)
+			}
+		}
+		state.Stacks[name] = record
+	})
+}
+
+// contains reports whether list holds item
+func contains(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns list with item removed
+func without(list []string, item string) []string {
+	var rest []string
+	for _, v := range list {
+		if v != item {
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
+
+// printSummary prints the outcome for every database of a stack
+func printSummary(results []result) {
+	printf("\n  %-24s %-10s %-8s %-6s %-10s %s\n", "NAME", "TYPE", "VERSION", "PORT", "STATUS", "NETWORKS")
+	printf("  %s\n", strings.Repeat("─", 76))
+	for _, r := range results {
+		status := fmt.Sprintf("%-10s", r.status)
+		switch r.status {
+		case statusCreated, statusRemoved:
+			status = success(status)
+		case statusFailed:
+			status = errColor(status)
+		case statusSkipped, statusMissing, statusKept:
+			status = warn(status)
+		}
+		networks := strings.Join(r.db.networkList(), ",")
+		if networks == "" {
+			networks = "-"
+		}
+		// The requested port is only known to be the actual one for new databases
+		port := "-"
+		if r.status == statusCreated {
+			port = r.db.port()
+		}
+		printf("  %s %-10s %-8s %-6s %s %s\n", info(fmt.Sprintf("%-24s", r.db.Name)), r.db.Type, r.db.version(), port, status, networks)
+	}
+	printf("\n")
+}
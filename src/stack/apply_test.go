@@ -0,0 +1,86 @@
+package stack
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awade12/go-db/src/utils"
+)
+
+// fakeRuntime puts a docker executable on PATH that fails every command, so
+// every container and network looks missing without a daemon
+func fakeRuntime(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+// pipeStdin replaces stdin with the read end of a pipe, as in CI or with
+// piped input, so no prompt can be answered
+func pipeStdin(t *testing.T) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+		w.Close()
+	})
+}
+
+func TestDownConfirmation(t *testing.T) {
+	tests := []struct {
+		name     string
+		yes      bool
+		wantErr  string
+		recorded bool // the stack is still in the state file afterwards
+	}{
+		{name: "refuses without a terminal", yes: false, wantErr: "pass --yes", recorded: true},
+		{name: "--yes skips the prompt", yes: true, recorded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			fakeRuntime(t)
+			pipeStdin(t)
+			output := utils.Output
+			utils.Output = io.Discard
+			t.Cleanup(func() { utils.Output = output })
+
+			path := filepath.Join(t.TempDir(), "shop.yaml")
+			if err := os.WriteFile(path, []byte("databases:\n  - name: shop-db\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := recordCreated("shop", utils.StackState{Containers: []string{"shop-db"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := Down(path, DownOptions{Yes: tt.yes})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Down() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Down() error = %v, want one containing %q", err, tt.wantErr)
+			}
+
+			state, err := utils.LoadState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := state.Stacks["shop"]; ok != tt.recorded {
+				t.Errorf("stack recorded = %v, want %v", ok, tt.recorded)
+			}
+		})
+	}
+}
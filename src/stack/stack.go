@@ -0,0 +1,319 @@
+package stack
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/awade12/go-db/src/databases/mysql"
+	"github.com/awade12/go-db/src/databases/postgres"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// Colors decorate printed output only; returned errors stay plain text
+var (
+	success  = utils.Success
+	info     = utils.Info
+	warn     = utils.Warn
+	errColor = utils.ErrColor
+)
+
+// printf writes human-readable output, which is discarded in machine-readable modes
+func printf(format string, a ...interface{}) {
+	fmt.Fprintf(utils.Output, format, a...)
+}
+
+// stackLabel marks the networks a stack created
+const stackLabel = "go-db.stack"
+
+// Database is one entry of a stack file. Besides name, type and depends-on,
+// an entry takes the same keys as a create-custom config file.
+type Database struct {
+	Name      string
+	Type      string   // postgres (default) or mysql
+	DependsOn []string // databases created before this one
+	postgres  *postgres.Config
+	mysql     *mysql.Config
+}
+
+// Stack is a set of databases created and removed together
+type Stack struct {
+	Name      string     // the file's name key, else its base name
+	Networks  []string   // networks every database joins, created as needed
+	Databases []Database // in dependency order
+}
+
+// Load reads a stack file (YAML, TOML or JSON) and orders its databases so
+// every database comes after the ones it depends on
+func Load(path string) (*Stack, error) {
+	s, problems := load(path)
+	if len(problems) > 0 {
+		return nil, problems[0]
+	}
+	return s, nil
+}
+
+// load reads a stack file like Load, but carries on past invalid entries and
+// returns every problem found. The databases stay in file order unless the
+// whole file is valid.
+func load(path string) (*Stack, []error) {
+	values, err := utils.ReadConfigFile(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var problems []error
+	invalid := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Errorf("invalid stack file %s: %s", path, fmt.Sprintf(format, a...)))
+	}
+
+	s := &Stack{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var entries []interface{}
+	malformed := false
+	for _, key := range keys {
+		value := values[key]
+		switch key {
+		case "name":
+			s.Name = fmt.Sprint(value)
+		case "networks":
+			if s.Networks, err = stringList(value); err != nil {
+				invalid("key \"networks\": %v", err)
+			}
+		case "databases":
+			list, ok := value.([]interface{})
+			if !ok {
+				invalid("key \"databases\" must be a list")
+				malformed = true
+				continue
+			}
+			entries = list
+		default:
+			invalid("unknown key %q, expected name, networks or databases", key)
+		}
+	}
+	if len(entries) == 0 {
+		// A databases key that isn't a list has been reported already
+		if !malformed {
+			invalid("no databases defined")
+		}
+		return s, problems
+	}
+
+	seen := make(map[string]bool)
+	parsed := true
+	for i, entry := range entries {
+		db, err := parseDatabase(entry)
+		if err != nil {
+			for _, problem := range splitErrors(err) {
+				invalid("databases[%d]: %v", i, problem)
+			}
+			parsed = false
+			continue
+		}
+		if seen[db.Name] {
+			invalid("database %s is defined twice", db.Name)
+			parsed = false
+			continue
+		}
+		seen[db.Name] = true
+		db.joinNetworks(s.Networks)
+		s.Databases = append(s.Databases, db)
+	}
+
+	// Dependencies on an entry that failed to parse would be reported as
+	// undefined, so they're only checked once every entry is valid
+	if parsed {
+		ordered, err := dependencyOrder(s.Databases)
+		if err != nil {
+			invalid("%v", err)
+		} else {
+			s.Databases = ordered
+		}
+	}
+	return s, problems
+}
+
+// parseDatabase decodes one stack entry into the configuration of its type
+func parseDatabase(entry interface{}) (Database, error) {
+	values, ok := entry.(map[string]interface{})
+	if !ok {
+		return Database{}, fmt.Errorf("expected a map of settings")
+	}
+
+	db := Database{Type: "postgres"}
+	db.Name, _ = values["name"].(string)
+	if db.Name == "" {
+		return db, fmt.Errorf("key \"name\" is required")
+	}
+	if t, ok := values["type"]; ok {
+		db.Type = strings.ToLower(fmt.Sprint(t))
+		delete(values, "type")
+	}
+	if deps, ok := values["depends-on"]; ok {
+		var err error
+		if db.DependsOn, err = stringList(deps); err != nil {
+			return db, fmt.Errorf("key \"depends-on\": %v", err)
+		}
+		delete(values, "depends-on")
+	}
+
+	switch db.Type {
+	case "postgres":
+		db.postgres = postgres.DefaultConfig(db.Name)
+		if err := utils.DecodeConfig(values, db.postgres); err != nil {
+			return db, fmt.Errorf("%s: %v", db.Name, err)
+		}
+		if err := db.postgres.Validate(); err != nil {
+			return db, prefixErrors(db.Name, err)
+		}
+	case "mysql":
+		db.mysql = mysql.DefaultConfig(db.Name)
+		if err := utils.DecodeConfig(values, db.mysql); err != nil {
+			return db, fmt.Errorf("%s: %v", db.Name, err)
+		}
+		if err := db.mysql.Validate(); err != nil {
+			return db, prefixErrors(db.Name, err)
+		}
+	default:
+		return db, fmt.Errorf("%s: unsupported type %q, expected postgres or mysql", db.Name, db.Type)
+	}
+	return db, nil
+}
+
+// joinNetworks adds the stack's shared networks to a database's own
+func (db *Database) joinNetworks(shared []string) {
+	var networks *[]string
+	if db.postgres != nil {
+		networks = &db.postgres.Networks
+	} else {
+		networks = &db.mysql.Networks
+	}
+	for _, network := range shared {
+		found := false
+		for _, joined := range *networks {
+			found = found || joined == network
+		}
+		if !found {
+			*networks = append(*networks, network)
+		}
+	}
+}
+
+// networkList returns the networks a database joins
+func (db *Database) networkList() []string {
+	if db.postgres != nil {
+		return db.postgres.Networks
+	}
+	return db.mysql.Networks
+}
+
+// port returns the host port a database is, or is to be, published on
+func (db *Database) port() string {
+	if db.postgres != nil {
+		return db.postgres.Port
+	}
+	return db.mysql.Port
+}
+
+// version returns the image version a database runs
+func (db *Database) version() string {
+	if db.postgres != nil {
+		return db.postgres.Version
+	}
+	return db.mysql.Version
+}
+
+// stringList accepts a list of strings, or a single string
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of names")
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("expected a list of names")
+}
+
+// dependencyOrder sorts databases so each comes after its dependencies,
+// keeping the file's order otherwise. Unknown dependencies and cycles are errors.
+func dependencyOrder(databases []Database) ([]Database, error) {
+	byName := make(map[string]Database)
+	for _, db := range databases {
+		byName[db.Name] = db
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var ordered []Database
+	var visit func(db Database, path []string) error
+	visit = func(db Database, path []string) error {
+		switch state[db.Name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, db.Name), " → "))
+		}
+		state[db.Name] = visiting
+		for _, dep := range db.DependsOn {
+			next, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("%s depends on %s, which is not defined", db.Name, dep)
+			}
+			if err := visit(next, append(path, db.Name)); err != nil {
+				return err
+			}
+		}
+		state[db.Name] = done
+		ordered = append(ordered, db)
+		return nil
+	}
+
+	for _, db := range databases {
+		if err := visit(db, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// networks returns every network the stack's databases join
+func (s *Stack) networks() []string {
+	set := make(map[string]bool)
+	for _, network := range s.Networks {
+		set[network] = true
+	}
+	for _, db := range s.Databases {
+		for _, network := range db.networkList() {
+			set[network] = true
+		}
+	}
+
+	var names []string
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exists reports whether a container or network exists
+func exists(kind, name string) bool {
+	return utils.RunDocker(kind, "inspect", name).Run() == nil
+}
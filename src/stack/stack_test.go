@@ -0,0 +1,55 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "databases is not a list",
+			file: "databases: shop-db\n",
+			want: []string{`key "databases" must be a list`},
+		},
+		{
+			name: "databases missing",
+			file: "name: shop\n",
+			want: []string{"no databases defined"},
+		},
+		{
+			name: "databases empty",
+			file: "databases: []\n",
+			want: []string{"no databases defined"},
+		},
+		{
+			name: "valid",
+			file: "databases:\n  - name: shop-db\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shop.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, problems := load(path)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("load() problems = %v, want %d", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i].Error(), want) {
+					t.Errorf("problem %d = %v, want one containing %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
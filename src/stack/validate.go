@@ -0,0 +1,125 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/awade12/go-db/src/databases"
+	"github.com/awade12/go-db/src/databases/postgres"
+	"github.com/awade12/go-db/src/utils"
+)
+
+// Validate checks a stack file without touching docker: every entry must
+// decode and pass its type's validation, names must be unique, dependencies
+// must resolve and no two databases may publish the same host port. Every
+// problem is printed; the returned error only counts them.
+func Validate(path string) error {
+	s, problems := load(path)
+	if s == nil {
+		return report(path, problems)
+	}
+	problems = append(problems, portProblems(path, s.Databases)...)
+	if err := report(path, problems); err != nil {
+		return err
+	}
+	printf("%s %s is valid (%d databases)\n", success("✔"), path, len(s.Databases))
+	return nil
+}
+
+// ValidateConfig checks a create-custom config file (YAML, TOML or JSON)
+// without touching docker. Like Validate, every problem is printed.
+func ValidateConfig(path string) error {
+	values, err := utils.ReadConfigFile(path)
+	if err != nil {
+		return report(path, []error{err})
+	}
+
+	name, _ := values["name"].(string)
+	if name == "" {
+		printf("%s %s has no name key; pass --name to create-custom\n", info("ℹ"), path)
+	}
+	cfg := postgres.DefaultConfig(name)
+	if err := utils.DecodeConfig(values, cfg); err != nil {
+		return report(path, []error{fmt.Errorf("invalid config file %s: %v", path, err)})
+	}
+	var problems []error
+	if err := cfg.Validate(); err != nil {
+		for _, problem := range splitErrors(err) {
+			problems = append(problems, fmt.Errorf("invalid config file %s: %v", path, problem))
+		}
+	}
+	if _, err := parsePort(cfg.Port); err != nil {
+		problems = append(problems, fmt.Errorf("invalid config file %s: %v", path, err))
+	}
+	if err := report(path, problems); err != nil {
+		return err
+	}
+	printf("%s %s is valid\n", success("✔"), path)
+	return nil
+}
+
+// portProblems reports invalid ports and host ports claimed by more than one
+// database. Create would move the second one to the next free port, but two
+// databases asking for the same port is most likely a mistake. A type's
+// default port is left out, since it's what an entry without a port gets.
+func portProblems(path string, dbs []Database) []error {
+	var problems []error
+	claimed := make(map[int]string)
+	for _, db := range dbs {
+		port, err := parsePort(db.port())
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid stack file %s: %s: %v", path, db.Name, err))
+			continue
+		}
+		if t, err := databases.Lookup(db.Type); err == nil && port == t.DefaultPort {
+			continue
+		}
+		if other, ok := claimed[port]; ok {
+			problems = append(problems, fmt.Errorf("invalid stack file %s: %s and %s both use port %d", path, other, db.Name, port))
+			continue
+		}
+		claimed[port] = db.Name
+	}
+	return problems
+}
+
+// parsePort checks that a port is a number between 1 and 65535
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q, expected a number between 1 and 65535", value)
+	}
+	return port, nil
+}
+
+// splitErrors returns the errors joined into err, or err alone
+func splitErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// prefixErrors prefixes each error joined into err, keeping them separate
+func prefixErrors(prefix string, err error) error {
+	var prefixed []error
+	for _, e := range splitErrors(err) {
+		prefixed = append(prefixed, fmt.Errorf("%s: %v", prefix, e))
+	}
+	return errors.Join(prefixed...)
+}
+
+// report prints every problem and returns an error counting them
+func report(path string, problems []error) error {
+	for _, problem := range problems {
+		printf("%s %v\n", errColor("✘"), problem)
+	}
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s has 1 problem", path)
+	}
+	return fmt.Errorf("%s has %d problems", path, len(problems))
+}
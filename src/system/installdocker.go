@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/awade12/go-db/src/utils"
 	"github.com/fatih/color"
 	"github.com/schollz/progressbar/v3"
 )
@@ -28,10 +29,80 @@ func InstallDocker() error {
 	case "darwin":
 		return installDockerDarwin()
 	default:
-		return fmt.Errorf("%s unsupported operating system: %s", errColor("✘"), runtime.GOOS)
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
+// distro is a Linux distribution family with its own way of installing Docker
+type distro int
+
+const (
+	distroUnknown distro = iota
+	distroDebian
+	distroRHEL
+	distroArch
+	distroAlpine
+	distroSUSE
+)
+
+func (d distro) String() string {
+	switch d {
+	case distroDebian:
+		return "Debian/Ubuntu"
+	case distroRHEL:
+		return "RHEL/CentOS/Fedora"
+	case distroArch:
+		return "Arch Linux"
+	case distroAlpine:
+		return "Alpine Linux"
+	case distroSUSE:
+		return "SUSE/openSUSE"
+	default:
+		return "unknown"
+	}
+}
+
+// detectDistro identifies the distribution family from its release files,
+// falling back to zypper for SUSE versions without /etc/SuSE-release
+func detectDistro() distro {
+	releaseFiles := []struct {
+		path   string
+		distro distro
+	}{
+		{"/etc/debian_version", distroDebian},
+		{"/etc/redhat-release", distroRHEL},
+		{"/etc/arch-release", distroArch},
+		{"/etc/alpine-release", distroAlpine},
+		{"/etc/SuSE-release", distroSUSE},
+		{"/etc/SUSE-brand", distroSUSE},
+	}
+	for _, f := range releaseFiles {
+		if _, err := os.Stat(f.path); err == nil {
+			return f.distro
+		}
+	}
+	if _, err := exec.LookPath("zypper"); err == nil {
+		return distroSUSE
+	}
+	return distroUnknown
+}
+
+// startCommand starts the Docker service with the distribution's init system
+func (d distro) startCommand() []string {
+	if d == distroAlpine {
+		return []string{"rc-service", "docker", "start"}
+	}
+	return []string{"systemctl", "start", "docker"}
+}
+
+// groupCommand adds a user to the docker group; Alpine has no usermod by default
+func (d distro) groupCommand(username string) []string {
+	if d == distroAlpine {
+		return []string{"addgroup", username, "docker"}
+	}
+	return []string{"usermod", "-aG", "docker", username}
+}
+
 func installDockerLinux() error {
 	// Check if Docker is already installed
 	if _, err := exec.LookPath("docker"); err == nil {
@@ -41,23 +112,35 @@ func installDockerLinux() error {
 
 	fmt.Printf("%s Detecting Linux distribution...\n", info("ℹ"))
 
-	// Detect the Linux distribution
-	if _, err := os.Stat("/etc/debian_version"); err == nil {
-		fmt.Printf("%s Debian/Ubuntu detected\n", success("✔"))
-		return installDockerDebian()
-	} else if _, err := os.Stat("/etc/redhat-release"); err == nil {
-		fmt.Printf("%s RHEL/CentOS/Fedora detected\n", success("✔"))
-		return installDockerRHEL()
+	d := detectDistro()
+	var steps []installStep
+	switch d {
+	case distroDebian:
+		steps = debianSteps()
+	case distroRHEL:
+		steps = rhelSteps()
+	case distroArch:
+		steps = archSteps()
+	case distroAlpine:
+		steps = alpineSteps()
+	case distroSUSE:
+		steps = suseSteps()
+	default:
+		return fmt.Errorf("unsupported Linux distribution")
 	}
 
-	return fmt.Errorf("%s unsupported Linux distribution", errColor("✘"))
+	fmt.Printf("%s %s detected\n", success("✔"), d)
+	return executeSteps(d, steps)
 }
 
-func installDockerDebian() error {
-	steps := []struct {
-		name    string
-		command []string
-	}{
+// installStep is one command of a Docker installation recipe, run with sudo
+type installStep struct {
+	name    string
+	command []string
+}
+
+func debianSteps() []installStep {
+	return []installStep{
 		{"Updating package list", []string{"apt-get", "update"}},
 		{"Installing prerequisites", []string{"apt-get", "install", "-y", "ca-certificates", "curl", "gnupg"}},
 		{"Creating keyring directory", []string{"install", "-m", "0755", "-d", "/etc/apt/keyrings"}},
@@ -67,21 +150,34 @@ func installDockerDebian() error {
 		{"Updating package list", []string{"apt-get", "update"}},
 		{"Installing Docker", []string{"apt-get", "install", "-y", "docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin"}},
 	}
-
-	return executeSteps(steps)
 }
 
-func installDockerRHEL() error {
-	steps := []struct {
-		name    string
-		command []string
-	}{
+func rhelSteps() []installStep {
+	return []installStep{
 		{"Installing DNF plugins", []string{"dnf", "install", "-y", "dnf-plugins-core"}},
 		{"Adding Docker repository", []string{"dnf", "config-manager", "--add-repo", "https://download.docker.com/linux/fedora/docker-ce.repo"}},
 		{"Installing Docker", []string{"dnf", "install", "-y", "docker-ce", "docker-ce-cli", "containerd.io", "docker-buildx-plugin", "docker-compose-plugin"}},
 	}
+}
+
+func archSteps() []installStep {
+	return []installStep{
+		{"Installing Docker", []string{"pacman", "-S", "--needed", "--noconfirm", "docker"}},
+	}
+}
 
-	return executeSteps(steps)
+func alpineSteps() []installStep {
+	return []installStep{
+		{"Installing Docker", []string{"apk", "add", "docker"}},
+		{"Enabling Docker at boot", []string{"rc-update", "add", "docker", "default"}},
+	}
+}
+
+func suseSteps() []installStep {
+	return []installStep{
+		{"Refreshing repositories", []string{"zypper", "--non-interactive", "refresh"}},
+		{"Installing Docker", []string{"zypper", "--non-interactive", "install", "docker"}},
+	}
 }
 
 func installDockerDarwin() error {
@@ -95,13 +191,19 @@ func installDockerDarwin() error {
 	fmt.Printf("%s 1. Visit %s\n", info("→"), "https://www.docker.com/products/docker-desktop")
 	fmt.Printf("%s 2. Download and install Docker Desktop for Mac\n", info("→"))
 	fmt.Printf("%s 3. Follow the installation instructions\n", info("→"))
-	return fmt.Errorf("%s manual installation required for macOS", warn("⚠"))
+	return fmt.Errorf("manual installation required for macOS")
 }
 
-func executeSteps(steps []struct {
-	name    string
-	command []string
-}) error {
+// sudoArgs builds the arguments for sudo. Without a terminal to type a password
+// into, sudo runs non-interactively and fails fast instead of waiting for input.
+func sudoArgs(command ...string) []string {
+	if utils.IsInteractive() {
+		return command
+	}
+	return append([]string{"-n"}, command...)
+}
+
+func executeSteps(d distro, steps []installStep) error {
 	totalSteps := len(steps)
 	bar := progressbar.NewOptions(totalSteps,
 		progressbar.OptionEnableColorCodes(true),
@@ -119,7 +221,7 @@ func executeSteps(steps []struct {
 	for i, step := range steps {
 		bar.Describe(fmt.Sprintf("[cyan]%s[reset]", step.name))
 
-		command := exec.Command("sudo", step.command...)
+		command := exec.Command("sudo", sudoArgs(step.command...)...)
 		command.Stdout = os.Stdout
 		command.Stderr = os.Stderr
 
@@ -136,9 +238,9 @@ func executeSteps(steps []struct {
 	fmt.Printf("%s Starting Docker service...\n", info("ℹ"))
 
 	// Start Docker service
-	startCmd := exec.Command("sudo", "systemctl", "start", "docker")
+	startCmd := exec.Command("sudo", sudoArgs(d.startCommand()...)...)
 	if err := startCmd.Run(); err != nil {
-		return fmt.Errorf("%s failed to start Docker service: %v", errColor("✘"), err)
+		return fmt.Errorf("failed to start Docker service: %v", err)
 	}
 
 	fmt.Printf("%s Docker service started\n", success("✔"))
@@ -146,7 +248,7 @@ func executeSteps(steps []struct {
 
 	// Add user to docker group
 	username := os.Getenv("USER")
-	groupCmd := exec.Command("sudo", "usermod", "-aG", "docker", username)
+	groupCmd := exec.Command("sudo", sudoArgs(d.groupCommand(username)...)...)
 	if err := groupCmd.Run(); err != nil {
 		fmt.Printf("%s Warning: Could not add user to docker group: %v\n", warn("⚠"), err)
 		fmt.Printf("%s You may need to use 'sudo' with docker commands\n", warn("⚠"))
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// certValidity is how long generated self-signed certificates stay valid
+const certValidity = 365 * 24 * time.Hour
+
+// GenerateSelfSignedCert writes a self-signed server certificate and its
+// private key to dir as server.crt and server.key. Each host becomes a
+// subject alternative name, as an IP address or a DNS name.
+func GenerateSelfSignedCert(dir, commonName string, hosts []string) (certPath, keyPath string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate private key: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate serial number: %v", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"go-db"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode private key: %v", err)
+	}
+
+	certPath = filepath.Join(dir, "server.crt")
+	keyPath = filepath.Join(dir, "server.key")
+	if err := writePEM(certPath, "CERTIFICATE", der, 0644); err != nil {
+		return "", "", err
+	}
+	if err := writePEM(keyPath, "EC PRIVATE KEY", keyDER, 0600); err != nil {
+		return "", "", err
+	}
+
+	// Make sure the pair loads before handing it to the server
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return "", "", fmt.Errorf("generated certificate is invalid: %v", err)
+	}
+	return certPath, keyPath, nil
+}
+
+// writePEM writes a single PEM block to path with the given permissions
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	// WriteFile keeps the mode of an existing file, so set it explicitly
+	return os.Chmod(path, perm)
+}
+
+// CertHosts returns the names a locally generated certificate should cover:
+// localhost, the loopback address, this machine's hostname and its outbound IP
+func CertHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		hosts = append(hosts, hostname)
+	}
+	if ip, err := GetOutboundIP(); err == nil {
+		hosts = append(hosts, ip)
+	}
+	return hosts
+}
@@ -72,8 +72,54 @@ func GetOutboundIP() (string, error) {
 	return localAddr.IP.String(), nil
 }
 
-// GetPublicIP attempts to get the public IPv4 address using HTTP requests
+// GetOutboundIPv6 gets the preferred outbound IPv6 address of this machine,
+// for IPv6-only and dual-stack hosts
+func GetOutboundIPv6() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to get network interfaces: %v", err)
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() != nil {
+				continue
+			}
+			// Link-local addresses need a zone and don't route off the link
+			if ipNet.IP.IsGlobalUnicast() {
+				return ipNet.IP.String(), nil
+			}
+		}
+	}
+
+	// Let the routing table pick the source address, as for IPv4
+	conn, err := net.Dial("udp6", "[2001:4860:4860::8888]:80")
+	if err != nil {
+		return "", fmt.Errorf("failed to get outbound IPv6 address: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// GetPublicIP attempts to get the public IPv4 address using HTTP requests. The
+// result is cached for an hour; with --no-public-ip no lookup is made at all.
 func GetPublicIP() (string, error) {
+	if noPublicIP {
+		return "", errNoPublicIP
+	}
+	if ip, ok := cachedPublicIP(); ok {
+		return ip, nil
+	}
+
 	// Try multiple IP lookup services
 	services := []string{
 		"http://ipv4.icanhazip.com",
@@ -106,6 +152,7 @@ func GetPublicIP() (string, error) {
 		// Validate that we got an IPv4 address
 		parsedIP := net.ParseIP(ip)
 		if parsedIP != nil && parsedIP.To4() != nil {
+			cachePublicIP(ip)
 			return ip, nil
 		}
 	}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadConfigFile reads a YAML (.yaml, .yml), TOML (.toml) or JSON file into a
+// generic map, choosing the format by the file extension
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		// Keep numbers as written, so 16.10 stays 16.10
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	return values, nil
+}
+
+// DecodeConfig decodes values into target through its JSON tags. Unknown keys
+// are rejected so that a misspelled setting is not silently ignored, and
+// numbers and booleans given for string fields (e.g. port: 5432 in YAML) are
+// accepted as strings. Errors name the offending key.
+func DecodeConfig(values map[string]interface{}, target interface{}) error {
+	if err := coerceScalars(values, reflect.TypeOf(target).Elem()); err != nil {
+		return err
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return describeDecodeError(err)
+	}
+	return nil
+}
+
+// coerceScalars turns the numbers and booleans given for the string fields of
+// struct type t, and for the values of its string maps (e.g. pg-param), into
+// strings, and parses durations such as 30s given for its time.Duration fields.
+// YAML and TOML parse 16.10 as the float 16.1, so a fractional number given for
+// a string field such as version is an error rather than a silent change.
+func coerceScalars(values map[string]interface{}, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		switch {
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			if s, ok := value.(string); ok {
+				if d, err := time.ParseDuration(s); err == nil {
+					values[key] = int64(d)
+				}
+			}
+		case field.Type.Kind() == reflect.String:
+			if f, ok := value.(float64); ok {
+				return fmt.Errorf("key %q: unquoted number %v may have lost trailing zeros (16.10 reads as 16.1); quote the value as written", key, f)
+			}
+			values[key] = coerceScalar(value)
+		case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.String:
+			if m, ok := value.(map[string]interface{}); ok {
+				for k, v := range m {
+					m[k] = coerceScalar(v)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// coerceScalar formats a number or boolean as a string
+func coerceScalar(value interface{}) interface{} {
+	switch value.(type) {
+	case int, int64, uint64, float64, bool, json.Number:
+		return fmt.Sprint(value)
+	}
+	return value
+}
+
+// describeDecodeError rewrites JSON decoding errors in terms of config keys
+func describeDecodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("key %q: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	if msg := err.Error(); strings.HasPrefix(msg, "json: unknown field ") {
+		return fmt.Errorf("unknown key %s", strings.TrimPrefix(msg, "json: unknown field "))
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrEncryptionUnsupported is returned where storage encryption can't be
+// detected, i.e. on every OS but Linux
+var ErrEncryptionUnsupported = fmt.Errorf("detecting encrypted storage is not supported on %s", runtime.GOOS)
+
+// EncryptedStorage reports whether a path lives on a dm-crypt (e.g. LUKS)
+// encrypted block device, along with the device backing it. Paths that don't
+// exist yet are checked at their nearest existing parent.
+func EncryptedStorage(path string) (bool, string, error) {
+	if runtime.GOOS != "linux" {
+		return false, "", ErrEncryptionUnsupported
+	}
+
+	path, err := existingParent(path)
+	if err != nil {
+		return false, "", err
+	}
+
+	output, err := exec.Command("findmnt", "-n", "-o", "SOURCE", "--target", path).Output()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to find the filesystem of %s: %v", path, err)
+	}
+	// btrfs subvolumes are reported as /dev/sdX[/subvolume]
+	device, _, _ := strings.Cut(strings.TrimSpace(string(output)), "[")
+	if !strings.HasPrefix(device, "/dev/") {
+		return false, device, nil
+	}
+
+	// lsblk --inverse lists the device and everything it is stacked on, so a
+	// filesystem on LVM on LUKS still shows the crypt layer
+	if output, err := exec.Command("lsblk", "--inverse", "--noheadings", "--output", "TYPE", device).Output(); err == nil {
+		for _, layer := range strings.Fields(string(output)) {
+			if layer == "crypt" {
+				return true, device, nil
+			}
+		}
+		return false, device, nil
+	}
+
+	// Without lsblk, ask cryptsetup about device-mapper targets directly
+	if strings.HasPrefix(device, "/dev/mapper/") {
+		output, err := exec.Command("cryptsetup", "status", filepath.Base(device)).Output()
+		if err == nil && strings.Contains(string(output), "is active") {
+			return true, device, nil
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return false, device, fmt.Errorf("neither lsblk nor cryptsetup is installed")
+		}
+	}
+	return false, device, nil
+}
+
+// existingParent returns the absolute form of path, or of its closest ancestor
+// that exists
+func existingParent(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("no existing parent directory of %s", path)
+		}
+		path = parent
+	}
+}
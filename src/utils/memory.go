@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// HostMemory returns the total physical memory of the host in bytes
+func HostMemory() (uint64, error) {
+	switch runtime.GOOS {
+	case "linux":
+		file, err := os.Open("/proc/meminfo")
+		if err != nil {
+			return 0, fmt.Errorf("failed to read /proc/meminfo: %v", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemTotal:" {
+				kb, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					return 0, fmt.Errorf("invalid MemTotal in /proc/meminfo: %v", err)
+				}
+				return kb * 1024, nil
+			}
+		}
+		return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+	case "darwin":
+		output, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read hw.memsize: %v", err)
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	default:
+		return 0, fmt.Errorf("detecting host memory is not supported on %s", runtime.GOOS)
+	}
+}
@@ -0,0 +1,10 @@
+package utils
+
+import (
+	"io"
+	"os"
+)
+
+// Output receives human-readable progress and status messages. Commands that
+// print machine-readable results point it at io.Discard so stdout stays clean.
+var Output io.Writer = os.Stdout
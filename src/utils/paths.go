@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigDir returns the directory go-db keeps its local state in (~/.go-db),
+// creating the requested subdirectory inside it if needed
+func ConfigDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %v", err)
+	}
+
+	dir := filepath.Join(append([]string{home, ".go-db"}, elem...)...)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	return dir, nil
+}
+
+// ExpandHome replaces a leading ~ in a host path with the user's home
+// directory, since docker takes paths literally and never expands it
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		if strings.HasPrefix(path, "~") {
+			return "", fmt.Errorf("%s: only ~ and ~/ are expanded, not ~user", path)
+		}
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %v", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+)
+
+// FindAvailablePort finds an available port in the inclusive range [startPort, endPort]
+func FindAvailablePort(startPort, endPort int) (int, error) {
+	for port := startPort; port <= endPort; port++ {
+		addr := fmt.Sprintf(":%d", port)
+		listener, err := net.Listen("tcp", addr)
+		if err == nil {
+			listener.Close()
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no available ports found in range %d-%d", startPort, endPort)
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// publicIPCacheTTL is how long a looked-up public IP is reused
+const publicIPCacheTTL = time.Hour
+
+// noPublicIP skips the public IP lookup, set with --no-public-ip
+var noPublicIP bool
+
+// errNoPublicIP is what GetPublicIP returns when the lookup is turned off
+var errNoPublicIP = errors.New("public IP lookup is disabled (--no-public-ip)")
+
+// SetNoPublicIP turns the public IP lookup off, for offline and air-gapped hosts
+func SetNoPublicIP(on bool) {
+	noPublicIP = on
+}
+
+// publicIPCachePath is the file the last looked-up public IP is kept in. It
+// lives in the user's own ~/.go-db/cache rather than the shared temporary
+// directory, where another user could plant the file or a symlink.
+func publicIPCachePath() (string, error) {
+	dir, err := ConfigDir("cache")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "publicip"), nil
+}
+
+// cachedPublicIP returns the cached public IP if it's younger than the TTL
+func cachedPublicIP() (string, bool) {
+	path, err := publicIPCachePath()
+	if err != nil {
+		return "", false
+	}
+	stat, err := os.Stat(path)
+	if err != nil || time.Since(stat.ModTime()) > publicIPCacheTTL {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	ip := strings.TrimSpace(string(data))
+	if parsed := net.ParseIP(ip); parsed == nil || parsed.To4() == nil {
+		return "", false
+	}
+	return ip, true
+}
+
+// cachePublicIP stores a looked-up public IP; failing to write it only costs
+// another lookup next time
+func cachePublicIP(ip string) {
+	if path, err := publicIPCachePath(); err == nil {
+		os.WriteFile(path, []byte(ip+"\n"), 0600)
+	}
+}
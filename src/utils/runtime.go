@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	dberrors "github.com/awade12/go-db/src/errors"
+	"github.com/fatih/color"
+)
+
+// Runtimes are the supported container CLIs. Podman is CLI-compatible with
+// docker, so every command is simply run with the selected binary.
+var Runtimes = []string{"docker", "podman"}
+
+// runtimeName is the runtime in use, resolved once by InitRuntime and
+// overridden by --runtime
+var runtimeName = "docker"
+
+// SetRuntime selects the container CLI used for all container commands
+func SetRuntime(name string) error {
+	for _, r := range Runtimes {
+		if name == r {
+			runtimeName = name
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported runtime %q, expected one of %s", name, strings.Join(Runtimes, ", "))
+}
+
+// InitRuntime resolves the default container CLI once at startup: $GODB_RUNTIME,
+// else runtime in ~/.go-db/config.json, else docker. An unsupported name is an
+// error, so a typo does not surface later as a missing binary.
+func InitRuntime() error {
+	if name := os.Getenv("GODB_RUNTIME"); name != "" {
+		if err := SetRuntime(name); err != nil {
+			return fmt.Errorf("GODB_RUNTIME: %v", err)
+		}
+		return nil
+	}
+	if settings, err := LoadSettings(); err == nil && settings.Runtime != "" {
+		if err := SetRuntime(settings.Runtime); err != nil {
+			return fmt.Errorf("runtime in ~/.go-db/config.json: %v", err)
+		}
+	}
+	return nil
+}
+
+// ContainerRuntime returns the container CLI in use: the one set with
+// --runtime, else the default resolved by InitRuntime
+func ContainerRuntime() string {
+	return runtimeName
+}
+
+// verbose makes RunDocker echo every command it builds, set with --verbose
+var verbose bool
+
+// dim is the color of the echoed commands
+var dim = color.New(color.Faint).SprintFunc()
+
+// SetVerbose turns the echoing of container commands on or off
+func SetVerbose(on bool) {
+	verbose = on
+}
+
+// RunDocker builds a command for the selected container CLI, like
+// exec.Command("docker", args...) but honoring --runtime and --verbose
+func RunDocker(args ...string) *exec.Cmd {
+	echoCommand(args)
+	return exec.Command(ContainerRuntime(), args...)
+}
+
+// RunDockerContext is RunDocker with a context that kills the command when done
+func RunDockerContext(ctx context.Context, args ...string) *exec.Cmd {
+	echoCommand(args)
+	return exec.CommandContext(ctx, ContainerRuntime(), args...)
+}
+
+// echoCommand prints a container command to stderr, shell-quoted so it can be
+// copied and run by hand. Password values are masked.
+func echoCommand(args []string) {
+	if !verbose {
+		return
+	}
+	words := []string{ContainerRuntime()}
+	for _, arg := range args {
+		words = append(words, shellQuote(maskSecret(arg)))
+	}
+	fmt.Fprintln(os.Stderr, dim("+ "+strings.Join(words, " ")))
+}
+
+// maskSecret hides the value of KEY=value arguments whose key names a
+// password, such as POSTGRES_PASSWORD or MYSQL_PWD
+func maskSecret(arg string) string {
+	key, _, found := strings.Cut(arg, "=")
+	upper := strings.ToUpper(key)
+	if found && (strings.Contains(upper, "PASSWORD") || strings.HasSuffix(upper, "PWD")) {
+		return key + "=****"
+	}
+	return arg
+}
+
+// shellQuote quotes an argument for a POSIX shell when it needs quoting
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`|&;<>()*?[]{}!#~") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// CheckRuntime verifies the selected container CLI is installed, suggesting
+// the other supported runtime when only that one is available
+func CheckRuntime() error {
+	name := ContainerRuntime()
+	if _, err := exec.LookPath(name); err == nil {
+		return nil
+	}
+
+	for _, other := range Runtimes {
+		if other == name {
+			continue
+		}
+		if _, err := exec.LookPath(other); err == nil {
+			return dberrors.Newf(dberrors.ErrDockerMissing, "%s is not installed, but %s is; use --runtime %s or set GODB_RUNTIME=%s", name, other, other, other)
+		}
+	}
+	return dberrors.Newf(dberrors.ErrDockerMissing, "%s is not installed; run go-db install-docker or install podman", name)
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the tool-wide preferences read from ~/.go-db/config.json
+type Settings struct {
+	OutputDir          string `json:"output_dir"`           // where generated artifacts are written
+	Runtime            string `json:"runtime"`              // container CLI: docker or podman
+	BackupReminderDays int    `json:"backup_reminder_days"` // backup age list and show warn about (default 7, negative to turn off)
+}
+
+// LoadSettings reads ~/.go-db/config.json; a missing file yields empty settings
+func LoadSettings() (Settings, error) {
+	var settings Settings
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return settings, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("invalid %s: %v", filepath.Join(dir, "config.json"), err)
+	}
+	return settings, nil
+}
+
+// OutputDir resolves the directory generated artifacts are written to: the
+// given directory if set, else output_dir from the settings, else the current
+// directory. The directory is created if needed.
+func OutputDir(dir string) (string, error) {
+	dir, err := ConfiguredOutputDir(dir)
+	if err != nil || dir != "" {
+		return dir, err
+	}
+	return ".", nil
+}
+
+// ConfiguredOutputDir is OutputDir without the current directory fallback:
+// it returns "" when neither the given directory nor output_dir is set, for
+// callers that keep their artifacts elsewhere by default
+func ConfiguredOutputDir(dir string) (string, error) {
+	if dir == "" {
+		settings, err := LoadSettings()
+		if err != nil {
+			return "", err
+		}
+		dir = settings.OutputDir
+	}
+	if dir == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory %s: %v", dir, err)
+	}
+	return dir, nil
+}
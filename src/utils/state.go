@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is what go-db records about its databases between runs, kept in
+// ~/.go-db/state.json
+type State struct {
+	Databases map[string]DatabaseState `json:"databases"`        // keyed by container name
+	Stacks    map[string]StackState    `json:"stacks,omitempty"` // keyed by stack name
+}
+
+// DatabaseState is the recorded state of one database
+type DatabaseState struct {
+	LastBackup *time.Time `json:"last_backup,omitempty"` // when the last backup completed
+}
+
+// StackState records what applying a stack created, as opposed to what
+// already existed, so tearing it down leaves shared resources alone
+type StackState struct {
+	Containers []string `json:"containers,omitempty"`
+	Networks   []string `json:"networks,omitempty"`
+}
+
+// statePath returns the location of the state file
+func statePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// LoadState reads the state file; a missing file yields an empty state
+func LoadState() (State, error) {
+	state := State{Databases: make(map[string]DatabaseState), Stacks: make(map[string]StackState)}
+
+	path, err := statePath()
+	if err != nil {
+		return state, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	if state.Databases == nil {
+		state.Databases = make(map[string]DatabaseState)
+	}
+	if state.Stacks == nil {
+		state.Stacks = make(map[string]StackState)
+	}
+	return state, nil
+}
+
+// SaveState writes the state file, replacing it atomically so a concurrent
+// reader never sees a partial file
+func SaveState(state State) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
+
+// stateLockTimeout is how long UpdateState waits for another go-db process to
+// finish its update. A lock file older than that is left over from a process
+// that died and is taken over.
+const stateLockTimeout = 10 * time.Second
+
+// lockState creates the state file's lock file, waiting while another process
+// holds it, and returns the function that releases it
+func lockState() (func(), error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	lock := path + ".lock"
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		file, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %v", path, err)
+		}
+		if stat, err := os.Stat(lock); err == nil && time.Since(stat.ModTime()) > stateLockTimeout {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other go-db is running", lock)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// UpdateState loads the state, applies change to it and saves it. A lock file
+// keeps concurrent go-db processes from losing each other's changes.
+func UpdateState(change func(*State)) error {
+	unlock, err := lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	change(&state)
+	return SaveState(state)
+}
+
+// RecordBackup stores the time a database was last backed up
+func RecordBackup(name string, at time.Time) error {
+	return UpdateState(func(state *State) {
+		db := state.Databases[name]
+		at = at.UTC()
+		db.LastBackup = &at
+		state.Databases[name] = db
+	})
+}
+
+// ForgetDatabase drops what the state file records about a database
+func ForgetDatabase(name string) error {
+	return UpdateState(func(state *State) {
+		delete(state.Databases, name)
+	})
+}
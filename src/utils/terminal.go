@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrNotInteractive is returned when a prompt would be needed but there is no
+// terminal to answer it, e.g. in CI or with piped input
+var ErrNotInteractive = errors.New("input is not a terminal")
+
+// IsInteractive reports whether stdin and stdout are both terminals, so
+// prompting the user is possible without hanging automation
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Confirm asks a yes/no question on stderr and defaults to no. Without a
+// terminal it never blocks and returns ErrNotInteractive instead; callers
+// should then require an explicit flag.
+func Confirm(question string) (bool, error) {
+	if !IsInteractive() {
+		return false, ErrNotInteractive
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s [y/N] ", Warn("?"), question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// ReadSecret reads a secret such as a password. On a terminal it prompts on
+// stderr without echoing; otherwise it reads the first line of stdin, so the
+// secret can be piped in.
+func ReadSecret(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read secret from stdin: %v", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConfirmWithoutTerminal(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin func(t *testing.T) *os.File
+	}{
+		{
+			name: "pipe",
+			stdin: func(t *testing.T) *os.File {
+				r, w, err := os.Pipe()
+				if err != nil {
+					t.Fatal(err)
+				}
+				// The write end stays open: reading would block forever
+				t.Cleanup(func() { w.Close() })
+				return r
+			},
+		},
+		{
+			name: "/dev/null",
+			stdin: func(t *testing.T) *os.File {
+				f, err := os.Open(os.DevNull)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return f
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.stdin(t)
+			stdin := os.Stdin
+			os.Stdin = f
+			t.Cleanup(func() {
+				os.Stdin = stdin
+				f.Close()
+			})
+
+			if IsInteractive() {
+				t.Fatal("IsInteractive() = true for a non-terminal stdin")
+			}
+
+			type answer struct {
+				ok  bool
+				err error
+			}
+			done := make(chan answer, 1)
+			go func() {
+				ok, err := Confirm("Remove everything?")
+				done <- answer{ok, err}
+			}()
+
+			select {
+			case a := <-done:
+				if a.ok || !errors.Is(a.err, ErrNotInteractive) {
+					t.Errorf("Confirm() = %v, %v, want false, ErrNotInteractive", a.ok, a.err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Confirm() blocked without a terminal")
+			}
+		})
+	}
+}